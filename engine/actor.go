@@ -49,6 +49,13 @@ type Actor struct {
 	game *Game
 }
 
+// ActorState is the part of an Actor that changes as it moves. It is useful
+// for implementing Snapshotter.
+type ActorState struct {
+	Pos geom.Int3
+	Rem geom.Float3 // fractional movement not yet applied to Pos
+}
+
 // BoundingBox returns the box Bounds.Add(Pos).
 func (a *Actor) BoundingBox() geom.Box {
 	return a.Bounds.Add(a.Pos)
@@ -74,13 +81,15 @@ func (a *Actor) CollidesAt(p geom.Int3) bool {
 // MoveX moves the actor x units in world space. It takes Game.VoxelScale into
 // account (so MoveX(x) moves the actor x/VoxelScale.X voxel units). onCollide
 // is called if a collision occurs, and the actor wil be in the colliding
-// position during the call.
+// position during the call. If the actor moved, it is reported with
+// Game.NotifyMoved.
 func (a *Actor) MoveX(x float64, onCollide func()) {
 	a.rem.X += x / a.game.VoxelScale.X
 	move := int(a.rem.X + 0.5) // Note: math.Round can lead to vibration
 	if move == 0 {
 		return
 	}
+	defer a.notifyMoved(a.Pos)
 	a.rem.X -= float64(move)
 	sign := geom.Sign(move)
 	for move != 0 {
@@ -105,6 +114,7 @@ func (a *Actor) MoveY(y float64, onCollide func()) {
 	if move == 0 {
 		return
 	}
+	defer a.notifyMoved(a.Pos)
 	a.rem.Y -= float64(move)
 	sign := geom.Sign(move)
 	for move != 0 {
@@ -129,6 +139,7 @@ func (a *Actor) MoveZ(z float64, onCollide func()) {
 	if move == 0 {
 		return
 	}
+	defer a.notifyMoved(a.Pos)
 	a.rem.Z -= float64(move)
 	sign := geom.Sign(move)
 	for move != 0 {
@@ -146,10 +157,52 @@ func (a *Actor) MoveZ(z float64, onCollide func()) {
 	}
 }
 
+// collidersIn returns all the Colliders within the component with ID domain.
+func collidersIn(g *Game, domain string) []Collider {
+	cd := g.Component(domain)
+	if cd == nil {
+		log.Printf("collision domain %q not found", domain)
+		return nil
+	}
+	var cs []Collider
+	g.Query(cd, ColliderType, nil, func(c any) error {
+		if cl, ok := c.(Collider); ok {
+			cs = append(cs, cl)
+		}
+		return nil
+	})
+	return cs
+}
+
+// notifyMoved calls Game.NotifyMoved if the actor is no longer at from.
+func (a *Actor) notifyMoved(from geom.Int3) {
+	if a.Pos != from && a.game != nil {
+		a.game.NotifyMoved(a)
+	}
+}
+
 // Prepare stores a reference to the game.
 func (a *Actor) Prepare(g *Game) error {
 	a.game = g
 	return nil
 }
 
+// SetPos moves the actor directly to p, ignoring collisions. Use this rather
+// than setting Pos directly, so that movement is reported.
+func (a *Actor) SetPos(p geom.Int3) {
+	defer a.notifyMoved(a.Pos)
+	a.Pos = p
+}
+
+// SetState restores the position and remainder from a previous State.
+func (a *Actor) SetState(s ActorState) {
+	defer a.notifyMoved(a.Pos)
+	a.Pos, a.rem = s.Pos, s.Rem
+}
+
+// State returns the current position and movement remainder.
+func (a *Actor) State() ActorState {
+	return ActorState{Pos: a.Pos, Rem: a.rem}
+}
+
 func (a *Actor) String() string { return "Actor@" + a.Pos.String() }
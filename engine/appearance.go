@@ -0,0 +1,67 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/DrJosh9000/ichigo/geom"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Appearance holds optional drawing adjustments for an image-drawing component
+// such as Sprite or Billboard. Flips, scaling, and rotation all happen about
+// Pivot, in that order.
+type Appearance struct {
+	FlipX, FlipY bool
+	Rotation     float64     // radians, clockwise
+	Scale        geom.Float2 // a zero component means 1
+	Pivot        image.Point // relative to the component's projected position
+	Tint         color.Color // multiplies colours (including alpha); nil means no tint
+}
+
+// apply concatenates the appearance adjustments onto opts. opts.GeoM should
+// already position the image relative to the component's projected position
+// (e.g. translated by DrawOffset), and the result should then be translated to
+// the projected position.
+func (a *Appearance) apply(opts *ebiten.DrawImageOptions) {
+	sx, sy := a.Scale.X, a.Scale.Y
+	if sx == 0 {
+		sx = 1
+	}
+	if sy == 0 {
+		sy = 1
+	}
+	if a.FlipX {
+		sx = -sx
+	}
+	if a.FlipY {
+		sy = -sy
+	}
+	if sx != 1 || sy != 1 || a.Rotation != 0 {
+		px, py := geom.CFloat(a.Pivot)
+		opts.GeoM.Translate(-px, -py)
+		opts.GeoM.Scale(sx, sy)
+		opts.GeoM.Rotate(a.Rotation)
+		opts.GeoM.Translate(px, py)
+	}
+	if a.Tint != nil {
+		c := color.NRGBAModel.Convert(a.Tint).(color.NRGBA)
+		opts.ColorM.Scale(float64(c.R)/255, float64(c.G)/255, float64(c.B)/255, float64(c.A)/255)
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/DrJosh9000/ichigo/geom"
+)
+
+func TestSpriteTransformAppearance(t *testing.T) {
+	g := &Game{Projection: geom.ElevationProjection{}}
+	tests := []struct {
+		name       string
+		appearance Appearance
+		in, want   geom.Float2
+	}{
+		{
+			name: "none",
+			in:   geom.Float2{X: 0, Y: 0},
+			want: geom.Float2{X: 95, Y: 35},
+		},
+		{
+			name:       "flip X mirrors DrawOffset",
+			appearance: Appearance{FlipX: true},
+			in:         geom.Float2{X: 0, Y: 0},
+			want:       geom.Float2{X: 105, Y: 35},
+		},
+		{
+			name:       "flip Y about pivot",
+			appearance: Appearance{FlipY: true, Pivot: image.Pt(0, -7)},
+			in:         geom.Float2{X: 0, Y: 14},
+			want:       geom.Float2{X: 95, Y: 37},
+		},
+		{
+			name:       "scale",
+			appearance: Appearance{Scale: geom.Float2{X: 2}},
+			in:         geom.Float2{X: 10, Y: 15},
+			want:       geom.Float2{X: 110, Y: 50},
+		},
+		{
+			name:       "rotate",
+			appearance: Appearance{Rotation: math.Pi / 2},
+			in:         geom.Float2{X: 15, Y: 15},
+			want:       geom.Float2{X: 100, Y: 60},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &Sprite{
+				Actor:      Actor{Pos: geom.Pt3(100, 50, 0), game: g},
+				Appearance: test.appearance,
+				DrawOffset: image.Pt(-5, -15),
+			}
+			opts := s.Transform()
+			x, y := opts.GeoM.Apply(test.in.X, test.in.Y)
+			got := geom.Float2{X: math.Round(x*1e6) / 1e6, Y: math.Round(y*1e6) / 1e6}
+			if got != test.want {
+				t.Errorf("s.Transform().GeoM.Apply(%v) = %v, want %v", test.in, got, test.want)
+			}
+		})
+	}
+}
+
+func TestAppearanceTint(t *testing.T) {
+	a := Appearance{Tint: color.NRGBA{R: 255, G: 0, B: 255, A: 128}}
+	b := &Billboard{Appearance: a, game: &Game{Projection: geom.ElevationProjection{}}}
+	opts := b.Transform()
+	got := color.NRGBAModel.Convert(opts.ColorM.Apply(color.White)).(color.NRGBA)
+	if want := (color.NRGBA{R: 255, G: 0, B: 255, A: 128}); got != want {
+		t.Errorf("b.Transform().ColorM.Apply(white) = %v, want %v", got, want)
+	}
+}
@@ -17,18 +17,64 @@ limitations under the License.
 package engine
 
 import (
+	"bufio"
+	"bytes"
 	"compress/gzip"
 	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
 	"io/fs"
-	"os"
-	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 )
 
+// GobzVersion is the current version of the gobz format. It must be
+// incremented whenever an exported field of a gob-encoded type is renamed,
+// removed, or changes type, and a migration should be registered (see
+// RegisterGobzMigration) if older files need fixing up.
+const GobzVersion = 1
+
+// gobzMagic begins the gunzipped contents of every versioned gobz file. Files
+// without it were written before gobz files had a header, and are treated as
+// version 0.
+const gobzMagic = "ichigobz"
+
+// ErrIncompatibleGobz is returned (wrapped) from LoadGobz when a file was
+// written by an incompatible version of the engine.
+var ErrIncompatibleGobz = errors.New("incompatible gobz file")
+
+var gobzMigrations = make(map[int]GobzMigration)
+
 type assetKey struct {
 	assets fs.FS
 	path   string
 }
 
+// GobzMigration updates a value decoded from a gobz file of one format version
+// so that it is correct for the next version.
+type GobzMigration func(dst any) error
+
+// RegisterGobzMigration registers a migration from version `from` to version
+// `from+1`. When a file older than GobzVersion is loaded, each migration
+// between its version and GobzVersion is applied in order. Since gob drops
+// fields that no longer exist, a migration for a renamed field relies on the
+// old field being kept around (at least until the next version). Like
+// gob.Register, it panics if a migration for the version already exists.
+func RegisterGobzMigration(from int, m GobzMigration) {
+	if _, exists := gobzMigrations[from]; exists {
+		panic(fmt.Sprintf("duplicate gobz migration from version %d", from))
+	}
+	gobzMigrations[from] = m
+}
+
+// gobzHeader follows gobzMagic in a versioned gobz file.
+type gobzHeader struct {
+	Version int
+	Types   map[string]map[string]string // type name -> field name -> field type
+}
+
 // LoadGobz gunzips and gob-decodes a component from a file from a FS.
 func LoadGobz(dst any, assets fs.FS, path string) error {
 	f, err := assets.Open(path)
@@ -36,32 +82,205 @@ func LoadGobz(dst any, assets fs.FS, path string) error {
 		return err
 	}
 	defer f.Close()
-	gz, err := gzip.NewReader(f)
-	if err != nil {
-		return err
+	if err := readGobz(dst, f); err != nil {
+		return fmt.Errorf("loading %s: %w", path, err)
 	}
-	return gob.NewDecoder(gz).Decode(dst)
+	return nil
 }
 
 // SaveGobz takes an object, gob-encodes it, gzips it, and writes to disk.
-// This requires running on something with a disk to write to (not JS)
+// This requires running on something with a disk to write to (not JS).
+// To save an asset back to an asset FS, use SaveGobzFS.
 func SaveGobz(src any, name string) error {
-	f, err := os.CreateTemp(".", filepath.Base(name))
+	var buf bytes.Buffer
+	if err := writeGobz(&buf, src); err != nil {
+		return err
+	}
+	return writeFileAtomic(name, buf.Bytes())
+}
+
+// readGobz gunzips and decodes a gobz file, checking the header and applying
+// migrations as needed.
+func readGobz(dst any, r io.Reader) error {
+	gz, err := gzip.NewReader(r)
 	if err != nil {
 		return err
 	}
-	defer os.Remove(f.Name())
-	defer f.Close()
+	br := bufio.NewReader(gz)
+	var hdr gobzHeader // version 0 if there is no header
+	if magic, err := br.Peek(len(gobzMagic)); err == nil && string(magic) == gobzMagic {
+		if _, err := br.Discard(len(gobzMagic)); err != nil {
+			return err
+		}
+		dec := gob.NewDecoder(br)
+		if err := dec.Decode(&hdr); err != nil {
+			return fmt.Errorf("decoding header: %w", err)
+		}
+		if hdr.Version > GobzVersion {
+			return fmt.Errorf("%w: written with format version %d, but this engine only supports up to version %d", ErrIncompatibleGobz, hdr.Version, GobzVersion)
+		}
+		// A retyped field usually makes decoding fail, so check the types
+		// that can be found before decoding (the types reachable from dst
+		// as it is now) first, to give a useful error.
+		if err := checkGobzTypes(hdr, dst); err != nil {
+			return err
+		}
+		if err := dec.Decode(dst); err != nil {
+			return fmt.Errorf("%w: decoding format version %d: %v", ErrIncompatibleGobz, hdr.Version, err)
+		}
+	} else if err := gob.NewDecoder(br).Decode(dst); err != nil {
+		return err
+	}
+
+	if hdr.Version == GobzVersion {
+		// Check again, now that dst may contain more types.
+		return checkGobzTypes(hdr, dst)
+	}
+	for v := hdr.Version; v < GobzVersion; v++ {
+		m := gobzMigrations[v]
+		if m == nil {
+			continue
+		}
+		if err := m(dst); err != nil {
+			return fmt.Errorf("migrating from format version %d: %w", v, err)
+		}
+	}
+	return nil
+}
+
+// checkGobzTypes returns an error if the header is for the current version but
+// the types in it have changed incompatibly compared with those found in dst.
+// If they have, someone forgot to increment GobzVersion.
+func checkGobzTypes(hdr gobzHeader, dst any) error {
+	if hdr.Version != GobzVersion {
+		return nil
+	}
+	if diffs := gobzTypeDiffs(hdr.Types, gobzTypes(dst)); len(diffs) > 0 {
+		return fmt.Errorf("%w: types changed since it was written, but format version is still %d:\n%s", ErrIncompatibleGobz, hdr.Version, strings.Join(diffs, "\n"))
+	}
+	return nil
+}
 
-	gz := gzip.NewWriter(f)
-	if err := gob.NewEncoder(gz).Encode(src); err != nil {
+// writeGobz writes src, gob-encoded and gzipped, with the current header.
+func writeGobz(w io.Writer, src any) error {
+	return writeGobzHeader(w, gobzHeader{
+		Version: GobzVersion,
+		Types:   gobzTypes(src),
+	}, src)
+}
+
+func writeGobzHeader(w io.Writer, hdr gobzHeader, src any) error {
+	gz := gzip.NewWriter(w)
+	if _, err := io.WriteString(gz, gobzMagic); err != nil {
 		return err
 	}
-	if err := gz.Close(); err != nil {
+	enc := gob.NewEncoder(gz)
+	if err := enc.Encode(hdr); err != nil {
 		return err
 	}
-	if err := f.Close(); err != nil {
+	if err := enc.Encode(src); err != nil {
 		return err
 	}
-	return os.Rename(f.Name(), name)
+	return gz.Close()
+}
+
+// gobzTypes walks the value to find every named struct type within it, and
+// records the exported fields of each.
+func gobzTypes(v any) map[string]map[string]string {
+	type ptrKey struct {
+		t reflect.Type
+		p uintptr
+	}
+	types := make(map[string]map[string]string)
+	seen := make(map[ptrKey]bool)
+	var walk func(reflect.Value)
+	walk = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Pointer:
+			if v.IsNil() {
+				return
+			}
+			k := ptrKey{v.Type(), v.Pointer()}
+			if seen[k] {
+				return
+			}
+			seen[k] = true
+			// Container encodes its items rather than its fields.
+			if c, ok := v.Interface().(*Container); ok {
+				for _, x := range c.items {
+					walk(reflect.ValueOf(x))
+				}
+				return
+			}
+			walk(v.Elem())
+
+		case reflect.Interface:
+			if !v.IsNil() {
+				walk(v.Elem())
+			}
+
+		case reflect.Struct:
+			t := v.Type()
+			if reflect.PointerTo(t).Implements(gobEncoderType) {
+				// Whatever it encodes, it isn't the fields.
+				return
+			}
+			if t.Name() != "" {
+				name := t.PkgPath() + "." + t.Name()
+				if _, found := types[name]; !found {
+					fields := make(map[string]string)
+					for i := 0; i < t.NumField(); i++ {
+						if f := t.Field(i); f.IsExported() {
+							fields[f.Name] = f.Type.String()
+						}
+					}
+					types[name] = fields
+				}
+			}
+			for i := 0; i < v.NumField(); i++ {
+				if t.Field(i).IsExported() {
+					walk(v.Field(i))
+				}
+			}
+
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i))
+			}
+
+		case reflect.Map:
+			for it := v.MapRange(); it.Next(); {
+				walk(it.Key())
+				walk(it.Value())
+			}
+		}
+	}
+	if v != nil {
+		walk(reflect.ValueOf(v))
+	}
+	return types
+}
+
+var gobEncoderType = reflect.TypeOf((*gob.GobEncoder)(nil)).Elem()
+
+// gobzTypeDiffs describes fields of types in old that were removed or changed
+// in cur. Types not in both, and fields only in cur, are not a problem.
+func gobzTypeDiffs(old, cur map[string]map[string]string) []string {
+	var diffs []string
+	for name, ofs := range old {
+		cfs, found := cur[name]
+		if !found {
+			continue
+		}
+		for f, ot := range ofs {
+			switch ct, found := cfs[f]; {
+			case !found:
+				diffs = append(diffs, fmt.Sprintf("%s.%s (%s) removed", name, f, ot))
+			case ct != ot:
+				diffs = append(diffs, fmt.Sprintf("%s.%s changed type from %s to %s", name, f, ot, ct))
+			}
+		}
+	}
+	sort.Strings(diffs)
+	return diffs
 }
@@ -0,0 +1,171 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type gobzTestThing struct {
+	Name  string
+	N     int
+	Inner *gobzTestThing
+}
+
+func TestGobzRoundTrip(t *testing.T) {
+	want := &gobzTestThing{Name: "a", N: 1, Inner: &gobzTestThing{Name: "b", N: 2}}
+	var buf bytes.Buffer
+	if err := writeGobz(&buf, want); err != nil {
+		t.Fatalf("writeGobz(want) = %v", err)
+	}
+	got := new(gobzTestThing)
+	if err := readGobz(got, &buf); err != nil {
+		t.Fatalf("readGobz(got) = %v", err)
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("round trip diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestGobzLegacy(t *testing.T) {
+	// Version 0 files have no header.
+	want := &gobzTestThing{Name: "old", N: 7}
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if err := gob.NewEncoder(gz).Encode(want); err != nil {
+		t.Fatalf("Encode(want) = %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gz.Close() = %v", err)
+	}
+
+	// Swap out the migrations for the duration of the test.
+	saved := gobzMigrations
+	t.Cleanup(func() { gobzMigrations = saved })
+	gobzMigrations = make(map[int]GobzMigration)
+	RegisterGobzMigration(0, func(dst any) error {
+		dst.(*gobzTestThing).N *= 2
+		return nil
+	})
+
+	got := new(gobzTestThing)
+	if err := readGobz(got, &buf); err != nil {
+		t.Fatalf("readGobz(got) = %v", err)
+	}
+	if diff := cmp.Diff(got, &gobzTestThing{Name: "old", N: 14}); diff != "" {
+		t.Errorf("legacy diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestGobzIncompatible(t *testing.T) {
+	tests := []struct {
+		name string
+		hdr  gobzHeader
+	}{
+		{
+			name: "newer version",
+			hdr:  gobzHeader{Version: GobzVersion + 1},
+		},
+		{
+			name: "changed field type",
+			hdr: gobzHeader{
+				Version: GobzVersion,
+				Types: map[string]map[string]string{
+					"github.com/DrJosh9000/ichigo/engine.gobzTestThing": {
+						"Name": "string",
+						"N":    "int64",
+					},
+				},
+			},
+		},
+		{
+			name: "removed field",
+			hdr: gobzHeader{
+				Version: GobzVersion,
+				Types: map[string]map[string]string{
+					"github.com/DrJosh9000/ichigo/engine.gobzTestThing": {
+						"Name":  "string",
+						"Gone":  "bool",
+						"Inner": "*engine.gobzTestThing",
+					},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeGobzHeader(&buf, test.hdr, &gobzTestThing{Name: "x"}); err != nil {
+				t.Fatalf("writeGobzHeader = %v", err)
+			}
+			err := readGobz(new(gobzTestThing), &buf)
+			if !errors.Is(err, ErrIncompatibleGobz) {
+				t.Errorf("readGobz = %v, want %v", err, ErrIncompatibleGobz)
+			}
+		})
+	}
+}
+
+// gobzTestThingOld is gobzTestThing, as it was before N became an int.
+type gobzTestThingOld struct {
+	Name string
+	N    string
+}
+
+func TestGobzRetypedField(t *testing.T) {
+	const name = "github.com/DrJosh9000/ichigo/engine.gobzTestThing"
+	tests := []struct {
+		name    string
+		types   map[string]map[string]string
+		wantMsg string
+	}{
+		{
+			name: "with types",
+			types: map[string]map[string]string{
+				name: {"Name": "string", "N": "string"},
+			},
+			wantMsg: name + ".N changed type from string to int",
+		},
+		{
+			name:    "without types",
+			wantMsg: "decoding format version",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			hdr := gobzHeader{Version: GobzVersion, Types: test.types}
+			if err := writeGobzHeader(&buf, hdr, &gobzTestThingOld{Name: "x", N: "seven"}); err != nil {
+				t.Fatalf("writeGobzHeader = %v", err)
+			}
+			err := readGobz(new(gobzTestThing), &buf)
+			if !errors.Is(err, ErrIncompatibleGobz) {
+				t.Errorf("readGobz = %v, want %v", err, ErrIncompatibleGobz)
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantMsg) {
+				t.Errorf("readGobz = %v, want error containing %q", err, test.wantMsg)
+			}
+		})
+	}
+}
@@ -37,9 +37,11 @@ func init() {
 	gob.Register(&Billboard{})
 }
 
-// Billboard draws an image at a position.
+// Billboard draws an image at a position. The Appearance adjustments pivot
+// about the projected position (the top-left of the image).
 type Billboard struct {
 	ID
+	Appearance
 	Hides
 	Pos geom.Int3
 	Src ImageRef
@@ -77,8 +79,10 @@ func (b *Billboard) String() string {
 	return fmt.Sprintf("Billboard@%v", b.Pos)
 }
 
-// Transform returns a translation by the projected position.
+// Transform returns the Appearance adjustments followed by a translation by the
+// projected position.
 func (b *Billboard) Transform() (opts ebiten.DrawImageOptions) {
+	b.Appearance.apply(&opts)
 	opts.GeoM.Translate(geom.CFloat(
 		geom.Project(b.game.Projection, b.Pos),
 	))
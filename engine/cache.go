@@ -0,0 +1,99 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"image"
+
+	"github.com/DrJosh9000/ichigo/geom"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// cacheChunkSize is the width and height of each image in a chunkCache.
+const cacheChunkSize = 256
+
+// chunkCache pre-renders a static layer into square chunk images, so that
+// drawing the layer only takes a few DrawImage calls. Chunks are rendered when
+// first needed, and kept until invalidated.
+type chunkCache struct {
+	images map[image.Point]*ebiten.Image
+}
+
+// draw draws the chunks that could be visible, rendering them as necessary.
+// bounds is the extent of the whole layer, and render should draw everything
+// in the layer, translated by -origin, onto dst.
+func (c *chunkCache) draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions, bounds image.Rectangle, render func(dst *ebiten.Image, origin image.Point)) {
+	if c.images == nil {
+		c.images = make(map[image.Point]*ebiten.Image)
+	}
+	if vr, ok := visibleRect(screen.Bounds(), opts.GeoM); ok {
+		bounds = bounds.Intersect(vr)
+	}
+	if bounds.Empty() {
+		return
+	}
+	size := image.Pt(cacheChunkSize, cacheChunkSize)
+	min := geom.CFloorDiv(bounds.Min, size)
+	max := geom.CFloorDiv(bounds.Max.Sub(image.Pt(1, 1)), size)
+
+	og := opts.GeoM
+	defer func() { opts.GeoM = og }()
+	var p image.Point
+	for p.Y = min.Y; p.Y <= max.Y; p.Y++ {
+		for p.X = min.X; p.X <= max.X; p.X++ {
+			origin := geom.CMul(p, size)
+			img := c.images[p]
+			if img == nil {
+				img = ebiten.NewImage(cacheChunkSize, cacheChunkSize)
+				render(img, origin)
+				c.images[p] = img
+			}
+			var mat ebiten.GeoM
+			mat.Translate(geom.CFloat(origin))
+			mat.Concat(og)
+			opts.GeoM = mat
+			screen.DrawImage(img, opts)
+		}
+	}
+}
+
+// invalidate discards all the chunks.
+func (c *chunkCache) invalidate() {
+	for _, img := range c.images {
+		img.Dispose()
+	}
+	c.images = nil
+}
+
+// invalidateRect discards the chunks overlapping r.
+func (c *chunkCache) invalidateRect(r image.Rectangle) {
+	if r.Empty() {
+		return
+	}
+	size := image.Pt(cacheChunkSize, cacheChunkSize)
+	min := geom.CFloorDiv(r.Min, size)
+	max := geom.CFloorDiv(r.Max.Sub(image.Pt(1, 1)), size)
+	var p image.Point
+	for p.Y = min.Y; p.Y <= max.Y; p.Y++ {
+		for p.X = min.X; p.X <= max.X; p.X++ {
+			if img := c.images[p]; img != nil {
+				img.Dispose()
+				delete(c.images, p)
+			}
+		}
+	}
+}
@@ -18,7 +18,9 @@ package engine
 
 import (
 	"encoding/gob"
+	"fmt"
 	"image"
+	"math"
 
 	"github.com/DrJosh9000/ichigo/geom"
 	"github.com/hajimehoshi/ebiten/v2"
@@ -29,11 +31,13 @@ var _ interface {
 	Identifier
 	Prepper
 	Scanner
+	Snapshotter
 	Transformer
 } = &Camera{}
 
 func init() {
 	gob.Register(&Camera{})
+	gob.Register(cameraState{})
 }
 
 // Camera models a camera that is viewing something.
@@ -50,51 +54,72 @@ type Camera struct {
 	Rotation float64     // radians
 	Zoom     float64     // unitless
 
+	// Viewport is the part of the screen the camera draws to, in logical
+	// screen coordinates. The zero value means the whole screen. Drawing is
+	// only confined to the viewport when drawn by SplitScreen.
+	Viewport image.Rectangle
+
 	game *Game
 }
 
 // PointAt points the camera at a particular centre point and zoom, but adjusts
 // for the bounds of the child component (if available).
 func (c *Camera) PointAt(centre geom.Int3, zoom float64) {
-	// Special sauce: if Child has a BoundingRect, make some adjustments
-	bnd, ok := c.Child.(BoundingRecter)
+	c.LookAt(geom.Project(c.game.Projection, centre), zoom)
+}
+
+// LookAt points the camera at a particular centre point (in projected
+// coordinates) and zoom, but adjusts for the bounds of the child component
+// (if available), taking Rotation into account.
+func (c *Camera) LookAt(centre image.Point, zoom float64) {
+	// Special sauce: if Child (or the world, for a camera in a SplitScreen)
+	// has a BoundingRect, make some adjustments
+	subject := c.Child
+	if ss, ok := c.game.Parent(c).(*SplitScreen); ok && subject == nil {
+		subject = ss.World
+	}
+	bnd, ok := subject.(BoundingRecter)
 	if !ok {
-		c.Centre = geom.Project(c.game.Projection, centre)
-		c.Zoom = zoom
+		c.Centre, c.Zoom = centre, zoom
 		return
 	}
 
 	// The child has boundaries; respect them.
 	br := bnd.BoundingRect()
 
+	// The camera frame is the viewport (scaled by 1/zoom) rotated about the
+	// centre. Its axis-aligned extent, at zoom 1, is ext.
+	vw, vh := geom.CFloat(c.viewport().Size())
+	sin, cos := math.Sincos(c.Rotation)
+	sin, cos = math.Abs(sin), math.Abs(cos)
+	extX, extY := cos*vw+sin*vh, sin*vw+cos*vh
+
 	// The lower bound on zoom is the larger of
-	// { (ScreenWidth / BoundsWidth), (ScreenHeight / BoundsHeight) }
-	sz := br.Size()
-	if z := float64(c.game.ScreenSize.X) / float64(sz.X); zoom < z {
+	// { (ExtentWidth / BoundsWidth), (ExtentHeight / BoundsHeight) }
+	bw, bh := geom.CFloat(br.Size())
+	if z := extX / bw; zoom < z {
 		zoom = z
 	}
-	if z := float64(c.game.ScreenSize.Y) / float64(sz.Y); zoom < z {
+	if z := extY / bh; zoom < z {
 		zoom = z
 	}
 
 	// If the configured centre puts the camera out of bounds, move it.
-	// Camera frame currently Rectangle{ centre ± (screen/(2*zoom)) }.
-	sw2, sh2 := geom.CFloat(c.game.ScreenSize.Div(2))
-	swz, shz := int(sw2/zoom), int(sh2/zoom)
-	cent := geom.Project(c.game.Projection, centre)
-	if cent.X-swz < br.Min.X {
-		cent.X = br.Min.X + swz
+	// Camera frame extent is Rectangle{ centre ± (ext/(2*zoom)) }.
+	swz, shz := int(extX/(2*zoom)), int(extY/(2*zoom))
+	if centre.X-swz < br.Min.X {
+		centre.X = br.Min.X + swz
 	}
-	if cent.Y-shz < br.Min.Y {
-		cent.Y = br.Min.Y + shz
+	if centre.Y-shz < br.Min.Y {
+		centre.Y = br.Min.Y + shz
 	}
-	if cent.X+swz > br.Max.X {
-		cent.X = br.Max.X - swz
+	if centre.X+swz > br.Max.X {
+		centre.X = br.Max.X - swz
 	}
-	if cent.Y+shz > br.Max.Y {
-		cent.Y = br.Max.Y - shz
+	if centre.Y+shz > br.Max.Y {
+		centre.Y = br.Max.Y - shz
 	}
-	c.Centre, c.Zoom = cent, zoom
+	c.Centre, c.Zoom = centre, zoom
 }
 
 // Prepare grabs a copy of game (needed for screen dimensions)
@@ -103,18 +128,57 @@ func (c *Camera) Prepare(game *Game) error {
 	return nil
 }
 
-// Scan visits c.Child.
+// Restore restores the camera controls from a snapshot.
+func (c *Camera) Restore(s any) error {
+	cs, ok := s.(cameraState)
+	if !ok {
+		return fmt.Errorf("snapshot type %T != cameraState", s)
+	}
+	c.Centre, c.Rotation, c.Zoom = cs.Centre, cs.Rotation, cs.Zoom
+	return nil
+}
+
+// Scan visits c.Child (if not nil; cameras used by SplitScreen have no child).
 func (c *Camera) Scan(visit VisitFunc) error {
+	if c.Child == nil {
+		return nil
+	}
 	return visit(c.Child)
 }
 
+// Snapshot returns the camera controls.
+func (c *Camera) Snapshot() (any, error) {
+	return cameraState{
+		Centre:   c.Centre,
+		Rotation: c.Rotation,
+		Zoom:     c.Zoom,
+	}, nil
+}
+
 func (c *Camera) String() string { return "Camera@" + c.Centre.String() }
 
-// Transform returns the camera transform.
+// Transform returns the camera transform. Centre is placed at the centre of
+// the viewport.
 func (c *Camera) Transform() (opts ebiten.DrawImageOptions) {
+	vp := c.viewport()
 	opts.GeoM.Translate(geom.CFloat(c.Centre.Mul(-1)))
 	opts.GeoM.Scale(c.Zoom, c.Zoom)
 	opts.GeoM.Rotate(c.Rotation)
-	opts.GeoM.Translate(geom.CFloat(c.game.ScreenSize.Div(2)))
+	opts.GeoM.Translate(geom.CFloat(vp.Min.Add(vp.Size().Div(2))))
 	return opts
 }
+
+// viewport returns Viewport, or the whole screen if Viewport is empty.
+func (c *Camera) viewport() image.Rectangle {
+	if c.Viewport.Empty() {
+		return image.Rectangle{Max: c.game.LogicalScreenSize()}
+	}
+	return c.Viewport
+}
+
+// cameraState is the snapshot of a Camera.
+type cameraState struct {
+	Centre   image.Point
+	Rotation float64
+	Zoom     float64
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"encoding/gob"
+	"fmt"
+	"image"
+	"math"
+	"math/rand"
+
+	"github.com/DrJosh9000/ichigo/geom"
+)
+
+var _ interface {
+	Disabler
+	Identifier
+	Prepper
+	Updater
+} = &CameraController{}
+
+func init() {
+	gob.Register(&CameraController{})
+}
+
+// CameraController moves a camera to follow a target component, with
+// smoothing, a deadzone, look-ahead, and screen shake. The camera is still
+// clamped to the bounds of its child (see Camera.LookAt).
+//
+// CameraController should be updated after the target, so it should be placed
+// after the target in the game tree.
+type CameraController struct {
+	ID
+	Disables
+	CameraID string // the *Camera to control
+	TargetID string // component to follow; must be a BoundingBoxer
+
+	Zoom      float64         // zoom to aim for; zero means 1
+	Smoothing float64         // time to catch up, in ticks; zero means no smoothing
+	Deadzone  image.Rectangle // relative to the focus, in projected pixels
+	Lookahead float64         // how many ticks of target velocity to lead by
+
+	ShakeOffset float64 // maximum shake displacement (pixels) at full trauma
+	ShakeAngle  float64 // maximum shake rotation (radians) at full trauma
+	TraumaDecay float64 // trauma lost per tick
+
+	game   *Game
+	camera *Camera
+	target BoundingBoxer
+
+	started   bool
+	last      geom.Float2 // target position on the previous tick
+	targetVel geom.Float2 // smoothed target velocity
+	goal      geom.Float2 // where the focus is heading
+	focus     geom.Float2 // smoothed focus
+	focusVel  geom.Float2
+	zoom      float64
+	zoomVel   float64
+	trauma    float64
+	shakeRot  float64 // rotation added by shake last tick
+}
+
+// AddTrauma adds to the current amount of trauma, which causes shaking. The
+// total trauma is limited to 1. Shake is proportional to the square of trauma.
+func (cc *CameraController) AddTrauma(t float64) {
+	cc.trauma = math.Min(1, math.Max(0, cc.trauma+t))
+}
+
+// Trauma returns the current amount of trauma.
+func (cc *CameraController) Trauma() float64 { return cc.trauma }
+
+// Prepare finds the camera and target.
+func (cc *CameraController) Prepare(game *Game) error {
+	cc.game = game
+	cam, ok := game.Component(cc.CameraID).(*Camera)
+	if !ok {
+		return fmt.Errorf("component %q not *Camera", cc.CameraID)
+	}
+	cc.camera = cam
+	tgt, ok := game.Component(cc.TargetID).(BoundingBoxer)
+	if !ok {
+		return fmt.Errorf("component %q not BoundingBoxer", cc.TargetID)
+	}
+	cc.target = tgt
+	cc.started = false
+	return nil
+}
+
+func (cc *CameraController) String() string { return "CameraController" }
+
+// Update moves the camera.
+func (cc *CameraController) Update() error {
+	zoom := cc.Zoom
+	if zoom == 0 {
+		zoom = 1
+	}
+	p := geom.Project(cc.game.Projection, cc.target.BoundingBox().Centre())
+	pos := geom.Float2{X: float64(p.X), Y: float64(p.Y)}
+	if !cc.started {
+		// Start where the target is, without any smoothing.
+		cc.last, cc.goal, cc.focus = pos, pos, pos
+		cc.targetVel, cc.focusVel = geom.Float2{}, geom.Float2{}
+		cc.zoom, cc.zoomVel = zoom, 0
+		cc.started = true
+	}
+
+	// Estimate target velocity (smoothed, to avoid jitter) for look-ahead.
+	const velSmoothing = 0.2
+	cc.targetVel.X += velSmoothing * (pos.X - cc.last.X - cc.targetVel.X)
+	cc.targetVel.Y += velSmoothing * (pos.Y - cc.last.Y - cc.targetVel.Y)
+	cc.last = pos
+	want := geom.Float2{
+		X: pos.X + cc.Lookahead*cc.targetVel.X,
+		Y: pos.Y + cc.Lookahead*cc.targetVel.Y,
+	}
+
+	// Only move the goal when the wanted point leaves the deadzone.
+	cc.goal.X = deadzone(cc.goal.X, want.X, cc.Deadzone.Min.X, cc.Deadzone.Max.X)
+	cc.goal.Y = deadzone(cc.goal.Y, want.Y, cc.Deadzone.Min.Y, cc.Deadzone.Max.Y)
+
+	cc.focus.X = smoothDamp(cc.focus.X, cc.goal.X, &cc.focusVel.X, cc.Smoothing)
+	cc.focus.Y = smoothDamp(cc.focus.Y, cc.goal.Y, &cc.focusVel.Y, cc.Smoothing)
+	cc.zoom = smoothDamp(cc.zoom, zoom, &cc.zoomVel, cc.Smoothing)
+
+	// Undo the previous shake rotation before clamping.
+	cc.camera.Rotation -= cc.shakeRot
+	cc.shakeRot = 0
+	cc.camera.LookAt(image.Pt(int(math.Round(cc.focus.X)), int(math.Round(cc.focus.Y))), cc.zoom)
+
+	// Shake is applied after clamping, so it isn't clamped away.
+	if cc.trauma > 0 {
+		shake := cc.trauma * cc.trauma
+		cc.camera.Centre = cc.camera.Centre.Add(image.Pt(
+			int(math.Round(cc.ShakeOffset*shake*(2*rand.Float64()-1))),
+			int(math.Round(cc.ShakeOffset*shake*(2*rand.Float64()-1))),
+		))
+		cc.shakeRot = cc.ShakeAngle * shake * (2*rand.Float64() - 1)
+		cc.camera.Rotation += cc.shakeRot
+		cc.trauma = math.Max(0, cc.trauma-cc.TraumaDecay)
+	}
+	return nil
+}
+
+// deadzone returns the new position of a focus coordinate, so that want lies
+// within [focus+min, focus+max].
+func deadzone(focus, want float64, min, max int) float64 {
+	if lo := focus + float64(min); want < lo {
+		return want - float64(min)
+	}
+	if hi := focus + float64(max); want > hi {
+		return want - float64(max)
+	}
+	return focus
+}
+
+// smoothDamp moves current towards target like a critically damped spring,
+// taking about smoothTime ticks to arrive. vel is the rate of change, which
+// smoothDamp reads and updates. If smoothTime <= 0, it returns target.
+func smoothDamp(current, target float64, vel *float64, smoothTime float64) float64 {
+	if smoothTime <= 0 {
+		*vel = 0
+		return target
+	}
+	ω := 2 / smoothTime
+	x := ω
+	exp := 1 / (1 + x + 0.48*x*x + 0.235*x*x*x)
+	change := current - target
+	temp := *vel + ω*change
+	*vel = (*vel - ω*temp) * exp
+	return target + (change+temp)*exp
+}
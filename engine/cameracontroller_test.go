@@ -0,0 +1,88 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestSmoothDamp(t *testing.T) {
+	x, vel := 0.0, 0.0
+	prev := x
+	for i := 0; i < 60; i++ {
+		x = smoothDamp(x, 100, &vel, 10)
+		if x < prev {
+			t.Fatalf("tick %d: x = %v < previous %v; want monotonic approach", i, x, prev)
+		}
+		if x > 100 {
+			t.Fatalf("tick %d: x = %v > 100; want no overshoot", i, x)
+		}
+		prev = x
+	}
+	if math.Abs(x-100) > 1 {
+		t.Errorf("after 60 ticks: x = %v, want about 100", x)
+	}
+
+	if got := smoothDamp(3, 7, &vel, 0); got != 7 || vel != 0 {
+		t.Errorf("smoothDamp(3, 7, &vel, 0) = %v (vel %v), want 7 (vel 0)", got, vel)
+	}
+}
+
+func TestDeadzone(t *testing.T) {
+	tests := []struct {
+		focus, want, result float64
+	}{
+		{focus: 0, want: 5, result: 0},
+		{focus: 0, want: 15, result: 5},
+		{focus: 0, want: -12, result: -2},
+	}
+	for _, test := range tests {
+		if got := deadzone(test.focus, test.want, -10, 10); got != test.result {
+			t.Errorf("deadzone(%v, %v, -10, 10) = %v, want %v", test.focus, test.want, got, test.result)
+		}
+	}
+}
+
+func TestCameraLookAtRotation(t *testing.T) {
+	world := &Scene{
+		Bounds: Bounds(image.Rect(0, 0, 1000, 1000)),
+		Child:  MakeContainer(),
+	}
+	cam := &Camera{Child: world}
+	g := &Game{
+		ScreenSize: image.Pt(200, 100),
+		Root:       &DrawDFS{Child: cam},
+	}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v, want nil", err)
+	}
+
+	// Unrotated, the frame is 200x100.
+	cam.LookAt(image.Pt(0, 0), 1)
+	if want := image.Pt(100, 50); cam.Centre != want {
+		t.Errorf("unrotated: Centre = %v, want %v", cam.Centre, want)
+	}
+
+	// Rotated a quarter turn, the frame is 100x200.
+	cam.Rotation = math.Pi / 2
+	cam.LookAt(image.Pt(0, 0), 1)
+	if want := image.Pt(50, 100); cam.Centre != want {
+		t.Errorf("rotated: Centre = %v, want %v", cam.Centre, want)
+	}
+}
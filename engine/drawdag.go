@@ -32,6 +32,8 @@ var _ interface {
 	Drawer
 	DrawManager
 	Hider
+	Identifier
+	MoveListener
 	Prepper
 	Registrar
 	Scanner
@@ -47,6 +49,7 @@ func init() {
 // It combines a DAG with a spatial index used when updating vertices to reduce
 // the number of tests between components.
 type DrawDAG struct {
+	ID
 	ChunkSize int
 	Child     any
 	Disables
@@ -55,11 +58,88 @@ type DrawDAG struct {
 	dag
 	boxCache map[DrawBoxer]geom.Box    // used to find components that moved
 	chunks   map[image.Point]drawerSet // chunk coord -> drawers with bounding rects intersecting chunk
+	dirty    map[DrawBoxer]struct{}    // MoveNotifiers that reported moving
+	polled   map[DrawBoxer]struct{}    // drawers that aren't MoveNotifiers
+	reasons  map[dagEdge]Constraint    // why each edge was added
+	reported map[string]struct{}       // cycles already logged
+	xrays    map[XRayTarget]struct{}   // registered XRayTargets
 	game     *Game
+
+	// Drawers below a Transformer (other than d) are drawn somewhere other
+	// than their bounding rect, so the chunks can't be used to cull them.
+	// transformed maps each such Transformer (the nearest ancestor of each
+	// drawer) to the drawers beneath it, and tfParent is the reverse.
+	transformed map[Transformer]drawerSet
+	tfParent    map[DrawBoxer]Transformer
+
+	reregistered int // in the last Update
+	cyclesBroken int // in the last Draw
+}
+
+// Constraint describes why DrawDAG must draw one drawer before another.
+type Constraint int
+
+// Constraint values.
+const (
+	NoConstraint         Constraint = iota
+	ConstraintZ                     // separated along Z
+	ConstraintX                     // separated along X (given the projection)
+	ConstraintY                     // separated along Y (given the projection)
+	ConstraintDrawBefore            // the first drawer's DrawBefore
+	ConstraintDrawAfter             // the second drawer's DrawAfter
+)
+
+func (c Constraint) String() string {
+	switch c {
+	case NoConstraint:
+		return "none"
+	case ConstraintZ:
+		return "Z"
+	case ConstraintX:
+		return "X"
+	case ConstraintY:
+		return "Y"
+	case ConstraintDrawBefore:
+		return "DrawBefore"
+	case ConstraintDrawAfter:
+		return "DrawAfter"
+	}
+	return fmt.Sprintf("Constraint(%d)", int(c))
+}
+
+// DrawDAGStats are statistics about a DrawDAG, returned by DrawDAG.Stats.
+type DrawDAGStats struct {
+	Vertices     int                // drawers in the DAG
+	Edges        int                // draw ordering constraints
+	EdgesBy      map[Constraint]int // edges, by the constraint that added them
+	Chunks       int                // non-empty chunks
+	MaxChunk     int                // most drawers in a single chunk
+	MeanChunk    float64            // mean drawers per non-empty chunk
+	Polled       int                // drawers checked every Update, not being MoveNotifiers
+	Reregistered int                // drawers re-registered in the last Update
+	CyclesBroken int                // cycles broken during the last Draw
 }
 
+func (s DrawDAGStats) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "vertices: %d\n", s.Vertices)
+	fmt.Fprintf(&sb, "edges: %d", s.Edges)
+	for c := ConstraintZ; c <= ConstraintDrawAfter; c++ {
+		fmt.Fprintf(&sb, " %v=%d", c, s.EdgesBy[c])
+	}
+	fmt.Fprintf(&sb, "\nchunks: %d (max %d, mean %.2f drawers)\n", s.Chunks, s.MaxChunk, s.MeanChunk)
+	fmt.Fprintf(&sb, "polled: %d\n", s.Polled)
+	fmt.Fprintf(&sb, "re-registered last tick: %d\n", s.Reregistered)
+	fmt.Fprintf(&sb, "cycles broken last frame: %d\n", s.CyclesBroken)
+	return sb.String()
+}
+
+// dagEdge is an edge (u -> v).
+type dagEdge struct{ u, v Drawer }
+
 // Draw draws everything in the DAG in topological order.
 func (d *DrawDAG) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
+	d.cyclesBroken = 0
 	if d.Hidden() {
 		return
 	}
@@ -77,8 +157,11 @@ func (d *DrawDAG) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
 			opts:   *opts,
 		},
 	}
-	// Draw everything in d.dag, where not hidden (itself or any parent)
-	d.dag.topWalk(func(x Drawer) {
+	// Draw everything in d.dag that could be on screen, where not hidden
+	// (itself or any parent)
+	vs := d.visible(screen.Bounds(), opts.GeoM)
+	fade, silhouettes := d.xray(vs)
+	d.dag.topWalkSubset(vs, d.breakCycle, func(x Drawer) {
 		// Is d hidden itself?
 		if h, ok := x.(Hider); ok && h.Hidden() {
 			cache[x] = state{hidden: true}
@@ -115,8 +198,151 @@ func (d *DrawDAG) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
 		if st.hidden {
 			return
 		}
+		if a, ok := fade[x]; ok {
+			o := st.opts
+			o.ColorM.Scale(1, 1, 1, a)
+			x.Draw(screen, &o)
+			return
+		}
 		x.Draw(screen, &st.opts)
 	})
+
+	// Draw silhouettes of covered targets over everything.
+	for _, t := range silhouettes {
+		st, drawn := cache[t]
+		if !drawn || st.hidden {
+			continue
+		}
+		o := st.opts
+		cm := t.XRayConfig().silhouette()
+		cm.Concat(o.ColorM)
+		o.ColorM = cm
+		t.Draw(screen, &o)
+	}
+}
+
+// xray finds the drawers in vs (or all drawers, if vs is nil) that cover an
+// XRayTarget in vs. It returns the alpha to draw each occluder with (for
+// targets using XRayFade), and the targets needing a silhouette.
+func (d *DrawDAG) xray(vs drawerSet) (fade map[Drawer]float64, silhouettes []XRayTarget) {
+	π := d.game.Projection
+	in := func(x Drawer) bool {
+		if vs == nil {
+			return true
+		}
+		_, ok := vs[x]
+		return ok
+	}
+	for t := range d.xrays {
+		x := t.XRayConfig()
+		if x == nil || x.Mode == XRayOff || !in(t) {
+			continue
+		}
+		tr := d.boxCache[t].BoundingRect(π)
+		covered := false
+		// Anything covering t on screen must overlap t, so it was tested when
+		// registered, and must be drawn after t.
+		for o := range d.dag[t].out {
+			ob, ok := o.(DrawBoxer)
+			if !ok || !in(o) || !d.boxCache[ob].BoundingRect(π).Overlaps(tr) {
+				continue
+			}
+			covered = true
+			if x.Mode != XRayFade {
+				continue
+			}
+			if fade == nil {
+				fade = make(map[Drawer]float64)
+			}
+			a := occluderAlpha(o, x)
+			if prev, seen := fade[o]; !seen || a < prev {
+				fade[o] = a
+			}
+		}
+		if covered && x.Mode == XRaySilhouette {
+			silhouettes = append(silhouettes, t)
+		}
+	}
+	return fade, silhouettes
+}
+
+// visible returns the drawers that could be drawn within the screen bounds
+// using geoM: those in chunks overlapping the visible part of the DAG, or
+// beneath Transformers and visible after transforming (see cullTransformed).
+// It returns nil if that can't be determined (meaning everything).
+func (d *DrawDAG) visible(screen image.Rectangle, geoM ebiten.GeoM) drawerSet {
+	vr, ok := visibleRect(screen, geoM)
+	if !ok {
+		return nil
+	}
+	// Chunks are computed with the same integer division as registerOne, which
+	// truncates towards zero, so allow a margin of one chunk.
+	min := vr.Min.Div(d.ChunkSize).Sub(image.Pt(1, 1))
+	max := vr.Max.Div(d.ChunkSize).Add(image.Pt(1, 1))
+	vs := make(drawerSet)
+	if (max.X-min.X+1)*(max.Y-min.Y+1) > len(d.chunks) {
+		// Fewer chunks exist than are visible (e.g. zoomed far out).
+		cr := image.Rectangle{min, max.Add(image.Pt(1, 1))}
+		for p, chunk := range d.chunks {
+			if !p.In(cr) {
+				continue
+			}
+			for x := range chunk {
+				vs[x] = struct{}{}
+			}
+		}
+		d.cullTransformed(vs, screen, geoM)
+		return vs
+	}
+	var p image.Point
+	for p.Y = min.Y; p.Y <= max.Y; p.Y++ {
+		for p.X = min.X; p.X <= max.X; p.X++ {
+			for x := range d.chunks[p] {
+				vs[x] = struct{}{}
+			}
+		}
+	}
+	d.cullTransformed(vs, screen, geoM)
+	return vs
+}
+
+// cullTransformed fixes up vs for drawers beneath Transformers. Where the
+// transforms between d and the drawers have no effect, the chunks were
+// correct. Otherwise, each drawer is tested against the visible rect in the
+// coordinates of its parent.
+func (d *DrawDAG) cullTransformed(vs drawerSet, screen image.Rectangle, geoM ebiten.GeoM) {
+	π := d.game.Projection
+	for tf, xs := range d.transformed {
+		tfGeoM := d.geoMAt(tf, geoM)
+		if tfGeoM == geoM {
+			continue
+		}
+		vr, ok := visibleRect(screen, tfGeoM)
+		for x := range xs {
+			if !ok || d.boxCache[x.(DrawBoxer)].BoundingRect(π).Overlaps(vr) {
+				vs[x] = struct{}{}
+			} else {
+				delete(vs, x)
+			}
+		}
+	}
+}
+
+// geoMAt returns the cumulative GeoM for drawing the subcomponents of x, given
+// geoM for d.
+func (d *DrawDAG) geoMAt(x any, geoM ebiten.GeoM) ebiten.GeoM {
+	var tfs []Transformer
+	for p := x; p != nil && p != d; p = d.game.Parent(p) {
+		if tf, ok := p.(Transformer); ok {
+			tfs = append(tfs, tf)
+		}
+	}
+	for i := len(tfs) - 1; i >= 0; i-- {
+		m := tfs[i].Transform().GeoM
+		m.Concat(geoM)
+		geoM = m
+	}
+	return geoM
 }
 
 // ManagesDrawingSubcomponents is present so DrawDAG is recognised as a
@@ -128,6 +354,13 @@ func (d *DrawDAG) Prepare(game *Game) error {
 	d.dag = make(dag)
 	d.boxCache = make(map[DrawBoxer]geom.Box)
 	d.chunks = make(map[image.Point]drawerSet)
+	d.dirty = make(map[DrawBoxer]struct{})
+	d.polled = make(map[DrawBoxer]struct{})
+	d.reasons = make(map[dagEdge]Constraint)
+	d.reported = make(map[string]struct{})
+	d.xrays = make(map[XRayTarget]struct{})
+	d.transformed = make(map[Transformer]drawerSet)
+	d.tfParent = make(map[DrawBoxer]Transformer)
 	d.game = game
 
 	// Because Game.LoadAndPrepare calls Prepare in a post-order walk, all the
@@ -141,27 +374,87 @@ func (d *DrawDAG) Scan(visit VisitFunc) error {
 	return visit(d.Child)
 }
 
+// Stats returns statistics about the DAG.
+func (d *DrawDAG) Stats() DrawDAGStats {
+	s := DrawDAGStats{
+		Vertices:     len(d.dag),
+		EdgesBy:      make(map[Constraint]int),
+		Polled:       len(d.polled),
+		Reregistered: d.reregistered,
+		CyclesBroken: d.cyclesBroken,
+	}
+	for _, e := range d.dag {
+		s.Edges += len(e.out)
+	}
+	for _, c := range d.reasons {
+		s.EdgesBy[c]++
+	}
+	total := 0
+	for _, chunk := range d.chunks {
+		if len(chunk) == 0 {
+			continue
+		}
+		s.Chunks++
+		total += len(chunk)
+		if len(chunk) > s.MaxChunk {
+			s.MaxChunk = len(chunk)
+		}
+	}
+	if s.Chunks > 0 {
+		s.MeanChunk = float64(total) / float64(s.Chunks)
+	}
+	return s
+}
+
 func (d *DrawDAG) String() string { return "DrawDAG" }
 
+// Moved marks the registered drawer that is, or is an ancestor of, component
+// as needing its bounding box checked during the next Update.
+func (d *DrawDAG) Moved(component any) {
+	for x := component; x != nil && x != d; x = d.game.Parent(x) {
+		db, ok := x.(DrawBoxer)
+		if !ok {
+			continue
+		}
+		if _, registered := d.boxCache[db]; !registered {
+			continue
+		}
+		if _, polled := d.polled[db]; !polled {
+			d.dirty[db] = struct{}{}
+		}
+		return
+	}
+}
+
 // Update checks for any changes to descendants, and updates its internal
 // data structures accordingly.
 func (d *DrawDAG) Update() error {
-	// Re-evaluate bounding boxes for all descendants. If a box has changed,
-	// fix up the edges by removing and re-adding the vertex.
+	// Re-evaluate bounding boxes for descendants that reported moving, and
+	// all the ones that can't report moving. If a box has changed, fix up the
+	// edges by removing and re-adding the vertex.
 	// Thanks once again to postorder traversal in Game.Update, this happens
 	// after all descendant updates.
 	// TODO: more flexible update ordering system...
 	var readd []DrawBoxer
-	for db, bb := range d.boxCache {
-		nbb := db.BoundingBox()
-		if bb != nbb {
-			d.unregisterOne(db)
+	check := func(db DrawBoxer) {
+		if d.boxCache[db] != db.BoundingBox() {
 			readd = append(readd, db)
 		}
 	}
+	for db := range d.polled {
+		check(db)
+	}
+	for db := range d.dirty {
+		check(db)
+	}
+	for db := range d.dirty {
+		delete(d.dirty, db)
+	}
 	for _, db := range readd {
+		d.unregisterOne(db)
 		d.registerOne(db)
 	}
+	d.reregistered = len(readd)
 	return nil
 }
 
@@ -188,6 +481,22 @@ func (d *DrawDAG) registerOne(x DrawBoxer) {
 	// Update the box cache
 	xb := x.BoundingBox()
 	d.boxCache[x] = xb
+	if mn, ok := x.(MoveNotifier); !ok || !mn.NotifiesMoves() {
+		d.polled[x] = struct{}{}
+	}
+	if xt, ok := x.(XRayTarget); ok {
+		d.xrays[xt] = struct{}{}
+	}
+	for p := d.game.Parent(x); p != nil && p != d; p = d.game.Parent(p) {
+		if tf, ok := p.(Transformer); ok {
+			if d.transformed[tf] == nil {
+				d.transformed[tf] = make(drawerSet)
+			}
+			d.transformed[tf][x] = struct{}{}
+			d.tfParent[x] = tf
+			break
+		}
+	}
 
 	// Update the reverse chunk map
 	xbr := xb.BoundingRect(d.game.Projection)
@@ -225,11 +534,10 @@ func (d *DrawDAG) registerOne(x DrawBoxer) {
 		if ybr := y.BoundingBox().BoundingRect(d.game.Projection); !xbr.Overlaps(ybr) {
 			continue
 		}
-		switch {
-		case drawOrderConstraint(x, y, πsign):
-			d.dag.addEdge(x, y)
-		case drawOrderConstraint(y, x, πsign):
-			d.dag.addEdge(y, x)
+		if c := drawOrderConstraint(x, y, πsign); c != NoConstraint {
+			d.constrain(x, y, c)
+		} else if c := drawOrderConstraint(y, x, πsign); c != NoConstraint {
+			d.constrain(y, x, c)
 		}
 	}
 }
@@ -257,51 +565,128 @@ func (d *DrawDAG) unregisterOne(x DrawBoxer) {
 			delete(d.chunks[image.Pt(i, j)], x)
 		}
 	}
-	// Remove from box cache
+	// Remove from box cache, etc
 	delete(d.boxCache, x)
+	delete(d.dirty, x)
+	delete(d.polled, x)
+	if xt, ok := x.(XRayTarget); ok {
+		delete(d.xrays, xt)
+	}
+	if tf, ok := d.tfParent[x]; ok {
+		delete(d.transformed[tf], x)
+		if len(d.transformed[tf]) == 0 {
+			delete(d.transformed, tf)
+		}
+		delete(d.tfParent, x)
+	}
 	// Remove from DAG
+	for u := range d.dag[x].in {
+		delete(d.reasons, dagEdge{u, x})
+	}
+	for v := range d.dag[x].out {
+		delete(d.reasons, dagEdge{x, v})
+	}
 	d.dag.removeVertex(x)
 }
 
-// drawOrderConstraint reports if there is a draw ordering constraint between u
-// and v (where u must draw before v).
-func drawOrderConstraint(u, v DrawBoxer, πsign image.Point) bool {
+// constrain adds the edge (u -> v), recording the reason.
+func (d *DrawDAG) constrain(u, v Drawer, c Constraint) {
+	d.dag.addEdge(u, v)
+	d.reasons[dagEdge{u, v}] = c
+}
+
+// breakCycle is called by topWalkSubset when it has to break a cycle. It logs
+// the cycle, if it hasn't been logged before.
+func (d *DrawDAG) breakCycle(cycle []Drawer) {
+	d.cyclesBroken++
+	desc := d.describeCycle(cycle)
+	if _, seen := d.reported[desc]; seen {
+		return
+	}
+	if len(d.reported) >= 100 {
+		// Don't remember too many.
+		d.reported = make(map[string]struct{})
+	}
+	d.reported[desc] = struct{}{}
+	log.Printf("breaking cycle in DrawDAG:\n%s", desc)
+}
+
+// describeCycle describes each vertex in the cycle, its bounding box, and the
+// constraint on the edge to the next vertex. The description is rotated to
+// start with the least line, so that the same cycle is described the same way
+// no matter where it was entered.
+func (d *DrawDAG) describeCycle(cycle []Drawer) string {
+	lines := make([]string, len(cycle))
+	for i, u := range cycle {
+		v := cycle[(i+1)%len(cycle)]
+		var box any = "(no box)"
+		if bb, ok := u.(BoundingBoxer); ok {
+			box = bb.BoundingBox()
+		}
+		lines[i] = fmt.Sprintf("\t%v %v draws before %v (%v)", u, box, v, d.reasons[dagEdge{u, v}])
+	}
+	least := 0
+	for i := range lines {
+		if lines[i] < lines[least] {
+			least = i
+		}
+	}
+	return strings.Join(append(lines[least:], lines[:least]...), "\n")
+}
+
+// drawOrderConstraint reports the draw ordering constraint between u and v
+// where u must draw before v, or NoConstraint if there isn't one.
+func drawOrderConstraint(u, v DrawBoxer, πsign image.Point) Constraint {
 	// Common logic for known interfaces (BoundingBoxer, ZPositioner), to
 	// simplify DrawOrderer implementations.
 	ub, vb := u.BoundingBox(), v.BoundingBox()
 	if ub.Min.Z >= vb.Max.Z { // u is in front of v
-		return false
+		return NoConstraint
 	}
 	if ub.Max.Z <= vb.Min.Z { // u is behind v
-		return true
+		return ConstraintZ
 	}
+	// For X and Y, "left" and "above" are relative to the projection sign.
 	if πsign.X != 0 {
-		if ub.Max.X*πsign.X <= vb.Min.X*πsign.X { // u is to the left of v
-			return false
+		umin, umax := signedRange(ub.Min.X, ub.Max.X, πsign.X)
+		vmin, vmax := signedRange(vb.Min.X, vb.Max.X, πsign.X)
+		if umax <= vmin { // u is to the left of v
+			return NoConstraint
 		}
-		if ub.Min.X*πsign.X >= vb.Max.X*πsign.X { // u is to the right of v
-			return true
+		if umin >= vmax { // u is to the right of v
+			return ConstraintX
 		}
 	}
 	if πsign.Y != 0 {
-		if ub.Max.Y*πsign.Y <= vb.Min.Y*πsign.Y { // u is above v
-			return false
+		umin, umax := signedRange(ub.Min.Y, ub.Max.Y, πsign.Y)
+		vmin, vmax := signedRange(vb.Min.Y, vb.Max.Y, πsign.Y)
+		if umax <= vmin { // u is above v
+			return NoConstraint
 		}
-		if ub.Min.Y*πsign.Y >= vb.Max.Y*πsign.Y { // u is below v
-			return true
+		if umin >= vmax { // u is below v
+			return ConstraintY
 		}
 	}
 
 	// Ask the components themselves if they have an opinion
 	if do, ok := u.(DrawOrderer); ok && do.DrawBefore(v) {
-		return true
+		return ConstraintDrawBefore
 	}
 	if do, ok := v.(DrawOrderer); ok && do.DrawAfter(u) {
-		return true
+		return ConstraintDrawAfter
 	}
 
 	// No relation
-	return false
+	return NoConstraint
+}
+
+// signedRange multiplies the range [min, max] by sign (which should be -1 or
+// 1), keeping it a range.
+func signedRange(min, max, sign int) (int, int) {
+	if sign < 0 {
+		return -max, -min
+	}
+	return min, max
 }
 
 type drawerSet map[Drawer]struct{}
@@ -374,15 +759,46 @@ func (d dag) removeEdge(u, v Drawer) {
 // O(|V|) temporary memory (for acyclic graphs) and a bit longer if it has to
 // break cycles.
 func (d dag) topWalk(visit func(Drawer)) {
-	// Count indegrees - indegree(v) = len(d[v].in) for each vertex v.
-	// If indegree(v) = 0, enqueue. Total: O(|V|).
-	queue := make([]Drawer, 0, len(d))
-	indegree := make(map[Drawer]int, len(d))
-	for v, e := range d {
-		if len(e.in) == 0 {
+	d.topWalkSubset(nil, nil, visit)
+}
+
+// topWalkSubset is like topWalk, but only visits vertices in vs, in
+// topological order of the subgraph induced by vs. If vs is nil, it visits
+// every vertex. If a cycle has to be broken, it is passed to cycle (if not
+// nil), in edge order.
+func (d dag) topWalkSubset(vs drawerSet, cycle func([]Drawer), visit func(Drawer)) {
+	// Count indegrees - indegree(v) = len(d[v].in) for each vertex v (only
+	// counting edges from vs, if not nil).
+	// If indegree(v) = 0, enqueue. Total: O(|V|) (or O(|E|) for a subset).
+	n := len(d)
+	if vs != nil {
+		n = len(vs)
+	}
+	queue := make([]Drawer, 0, n)
+	indegree := make(map[Drawer]int, n)
+	count := func(v Drawer, e edges) {
+		deg := len(e.in)
+		if vs != nil {
+			deg = 0
+			for u := range e.in {
+				if _, in := vs[u]; in {
+					deg++
+				}
+			}
+		}
+		if deg == 0 {
 			queue = append(queue, v)
 		} else {
-			indegree[v] = len(e.in)
+			indegree[v] = deg
+		}
+	}
+	if vs == nil {
+		for v, e := range d {
+			count(v, e)
+		}
+	} else {
+		for v := range vs {
+			count(v, d[v])
 		}
 	}
 
@@ -397,7 +813,11 @@ func (d dag) topWalk(visit func(Drawer)) {
 					mind, minv = d, v
 				}
 			}
-			log.Printf("breaking cycle in 'DAG' by enqueueing %v with indegree %d", minv, mind)
+			if cycle != nil {
+				cycle(d.findCycle(minv, indegree))
+			} else {
+				log.Printf("breaking cycle in 'DAG' by enqueueing %v with indegree %d", minv, mind)
+			}
 			queue = append(queue, minv)
 			delete(indegree, minv)
 		}
@@ -413,7 +833,8 @@ func (d dag) topWalk(visit func(Drawer)) {
 			// indegree is now 0.
 			for v := range d[u].out {
 				if _, ready := indegree[v]; !ready {
-					// Vertex already drawn. This happens if there was a cycle.
+					// Vertex already drawn (this happens if there was a
+					// cycle), or not in vs.
 					continue
 				}
 				indegree[v]--
@@ -425,3 +846,32 @@ func (d dag) topWalk(visit func(Drawer)) {
 		}
 	}
 }
+
+// findCycle finds a cycle through vertices in remaining, by following in-edges
+// from start. Every vertex in remaining must have an in-edge from another
+// vertex in remaining. The cycle is returned in edge order.
+func (d dag) findCycle(start Drawer, remaining map[Drawer]int) []Drawer {
+	index := make(map[Drawer]int)
+	var path []Drawer
+	for v := start; v != nil; {
+		if i, seen := index[v]; seen {
+			// path[i:] is a cycle, but backwards.
+			cyc := path[i:]
+			for a, b := 0, len(cyc)-1; a < b; a, b = a+1, b-1 {
+				cyc[a], cyc[b] = cyc[b], cyc[a]
+			}
+			return cyc
+		}
+		index[v] = len(path)
+		path = append(path, v)
+		var next Drawer
+		for u := range d[v].in {
+			if _, ok := remaining[u]; ok {
+				next = u
+				break
+			}
+		}
+		v = next
+	}
+	return nil
+}
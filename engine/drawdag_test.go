@@ -17,6 +17,7 @@ limitations under the License.
 package engine
 
 import (
+	"image"
 	"testing"
 
 	"github.com/DrJosh9000/ichigo/geom"
@@ -202,3 +203,286 @@ func TestTopWalkToleratesCycle(t *testing.T) {
 		t.Errorf("topWalk visited vertices wrong number of times - diff:\n%s", diff)
 	}
 }
+
+func TestTopWalkSubset(t *testing.T) {
+	// u -> v -> w, u -> w, x -> w
+	u := fakeDrawBoxer("u")
+	v := fakeDrawBoxer("v")
+	w := fakeDrawBoxer("w")
+	x := fakeDrawBoxer("x")
+	d := make(dag)
+	d.addEdge(u, v)
+	d.addEdge(v, w)
+	d.addEdge(u, w)
+	d.addEdge(x, w)
+
+	var got []Drawer
+	d.topWalkSubset(drawerSet{w: {}, u: {}}, nil, func(x Drawer) {
+		got = append(got, x)
+	})
+	want := []Drawer{u, w}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("topWalkSubset visited in wrong order - diff:\n%s", diff)
+	}
+}
+
+type fakeMover struct {
+	Actor
+}
+
+func (*fakeMover) Draw(*ebiten.Image, *ebiten.DrawImageOptions) {}
+func (*fakeMover) NotifiesMoves() bool                          { return true }
+func (m *fakeMover) Scan(visit VisitFunc) error                 { return visit(&m.Actor) }
+
+func TestDrawDAGMoveNotifications(t *testing.T) {
+	box := geom.Box{Max: geom.Pt3(4, 4, 4)}
+	front := &fakeMover{Actor{Pos: geom.Pt3(0, 0, 8), Bounds: box}}
+	back := &fakeMover{Actor{Pos: geom.Pt3(20, 0, 0), Bounds: box}}
+	static := fakeDrawBoxer("static")
+	d := &DrawDAG{
+		ChunkSize: 16,
+		Child:     MakeContainer(front, back, static),
+	}
+	g := &Game{Root: d}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v", err)
+	}
+	if _, polled := d.polled[static]; !polled {
+		t.Error("static drawer is not polled")
+	}
+	for _, m := range []*fakeMover{front, back} {
+		if _, polled := d.polled[m]; polled {
+			t.Errorf("%v is polled, but is a MoveNotifier", m)
+		}
+	}
+	if _, edge := d.dag[back].out[front]; edge {
+		t.Error("edge back -> front exists before overlapping")
+	}
+
+	back.SetPos(geom.Pt3(2, 0, 0))
+	if _, dirty := d.dirty[back]; !dirty {
+		t.Error("back not dirty after SetPos")
+	}
+	if err := d.Update(); err != nil {
+		t.Fatalf("d.Update() = %v", err)
+	}
+	if len(d.dirty) != 0 {
+		t.Errorf("d.dirty = %v after Update, want empty", d.dirty)
+	}
+	if got, want := d.boxCache[back], box.Add(geom.Pt3(2, 0, 0)); got != want {
+		t.Errorf("d.boxCache[back] = %v, want %v", got, want)
+	}
+	if _, edge := d.dag[back].out[front]; !edge {
+		t.Error("edge back -> front missing after moving")
+	}
+}
+
+func TestDrawDAGSpritePolling(t *testing.T) {
+	d := &DrawDAG{ChunkSize: 16, Child: MakeContainer()}
+	g := &Game{Root: d}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v", err)
+	}
+	box := geom.Box{Max: geom.Pt3(4, 4, 4)}
+	polled := &Sprite{Actor: Actor{Bounds: box}}
+	notifier := &Sprite{Actor: Actor{Bounds: box}, NotifyMoves: true}
+	d.registerOne(polled)
+	d.registerOne(notifier)
+	if _, ok := d.polled[polled]; !ok {
+		t.Error("sprite without NotifyMoves is not polled")
+	}
+	if _, ok := d.polled[notifier]; ok {
+		t.Error("sprite with NotifyMoves is polled")
+	}
+
+	// Assigning Pos directly is noticed when polled.
+	polled.Actor.Pos = geom.Pt3(40, 0, 0)
+	if err := d.Update(); err != nil {
+		t.Fatalf("d.Update() = %v", err)
+	}
+	if got, want := d.boxCache[polled], box.Add(geom.Pt3(40, 0, 0)); got != want {
+		t.Errorf("d.boxCache[polled] = %v, want %v", got, want)
+	}
+}
+
+func TestTopWalkReportsCycle(t *testing.T) {
+	// x -> u -> v -> w -> u
+	u := fakeDrawBoxer("u")
+	v := fakeDrawBoxer("v")
+	w := fakeDrawBoxer("w")
+	x := fakeDrawBoxer("x")
+	d := make(dag)
+	d.addEdge(x, u)
+	d.addEdge(u, v)
+	d.addEdge(v, w)
+	d.addEdge(w, u)
+
+	var cycles [][]Drawer
+	d.topWalkSubset(nil, func(c []Drawer) {
+		cycles = append(cycles, c)
+	}, func(Drawer) {})
+	if len(cycles) != 1 {
+		t.Fatalf("got %d cycles, want 1", len(cycles))
+	}
+	got := cycles[0]
+	// Rotate so that u is first.
+	for i, c := range got {
+		if c == u {
+			got = append(got[i:], got[:i]...)
+			break
+		}
+	}
+	want := []Drawer{u, v, w}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("cycle diff:\n%s", diff)
+	}
+}
+
+func TestDrawDAGStats(t *testing.T) {
+	box := geom.Box{Max: geom.Pt3(4, 4, 4)}
+	front := &fakeMover{Actor{Pos: geom.Pt3(0, 0, 8), Bounds: box}}
+	back := &fakeMover{Actor{Pos: geom.Pt3(2, 0, 0), Bounds: box}}
+	far := &fakeMover{Actor{Pos: geom.Pt3(40, 0, 0), Bounds: box}}
+	d := &DrawDAG{
+		ChunkSize: 16,
+		Child:     MakeContainer(front, back, far),
+	}
+	g := &Game{Root: d}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v", err)
+	}
+	if got, want := d.reasons[dagEdge{back, front}], ConstraintZ; got != want {
+		t.Errorf("reason for edge back -> front = %v, want %v", got, want)
+	}
+
+	far.SetPos(geom.Pt3(41, 0, 0))
+	if err := d.Update(); err != nil {
+		t.Fatalf("d.Update() = %v", err)
+	}
+	want := DrawDAGStats{
+		Vertices:     3,
+		Edges:        1,
+		EdgesBy:      map[Constraint]int{ConstraintZ: 1},
+		Chunks:       2,
+		MaxChunk:     2,
+		MeanChunk:    1.5,
+		Reregistered: 1,
+	}
+	if diff := cmp.Diff(d.Stats(), want); diff != "" {
+		t.Errorf("d.Stats() diff:\n%s", diff)
+	}
+
+	got := d.describeCycle([]Drawer{front, back})
+	wantDesc := "\tActor@(0,0,8) (0,0,8)-(4,4,12) draws before Actor@(2,0,0) (none)\n" +
+		"\tActor@(2,0,0) (2,0,0)-(6,4,4) draws before Actor@(0,0,8) (Z)"
+	if got != wantDesc {
+		t.Errorf("d.describeCycle(front, back) = %q, want %q", got, wantDesc)
+	}
+}
+
+type fakeXRayer struct {
+	fakeMover
+	xray *XRay
+}
+
+func (f *fakeXRayer) XRayConfig() *XRay { return f.xray }
+
+type fakeOccluder struct {
+	fakeMover
+	alpha float64
+}
+
+func (f *fakeOccluder) XRayAlpha() float64 { return f.alpha }
+
+func TestDrawDAGXRay(t *testing.T) {
+	box := geom.Box{Max: geom.Pt3(4, 4, 4)}
+	target := &fakeXRayer{
+		fakeMover: fakeMover{Actor{Pos: geom.Pt3(0, 0, 0), Bounds: box}},
+		xray:      &XRay{Mode: XRayFade, Alpha: 0.25},
+	}
+	front := &fakeMover{Actor{Pos: geom.Pt3(2, 0, 8), Bounds: box}}
+	opaque := &fakeOccluder{
+		fakeMover: fakeMover{Actor{Pos: geom.Pt3(0, 2, 8), Bounds: box}},
+		alpha:     1,
+	}
+	behind := &fakeMover{Actor{Pos: geom.Pt3(0, 0, -8), Bounds: box}}
+	aside := &fakeMover{Actor{Pos: geom.Pt3(10, 0, 8), Bounds: box}}
+	d := &DrawDAG{
+		ChunkSize: 16,
+		Child:     MakeContainer(target, front, opaque, behind, aside),
+	}
+	g := &Game{Root: d}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v", err)
+	}
+
+	fade, silhouettes := d.xray(nil)
+	wantFade := map[Drawer]float64{front: 0.25, opaque: 1}
+	if diff := cmp.Diff(fade, wantFade); diff != "" {
+		t.Errorf("fade diff:\n%s", diff)
+	}
+	if len(silhouettes) != 0 {
+		t.Errorf("silhouettes = %v, want none", silhouettes)
+	}
+
+	target.xray.Mode = XRaySilhouette
+	fade, silhouettes = d.xray(nil)
+	if len(fade) != 0 {
+		t.Errorf("fade = %v, want none", fade)
+	}
+	if len(silhouettes) != 1 || silhouettes[0] != target {
+		t.Errorf("silhouettes = %v, want [target]", silhouettes)
+	}
+
+	// Only consider drawers that are visible.
+	target.xray.Mode = XRayFade
+	fade, _ = d.xray(drawerSet{target: {}, opaque: {}})
+	if diff := cmp.Diff(fade, map[Drawer]float64{opaque: 1}); diff != "" {
+		t.Errorf("fade (subset) diff:\n%s", diff)
+	}
+}
+
+func TestDrawDAGCullsTransformed(t *testing.T) {
+	// The camera shows DAG-space x in [40, 360). The Parallax child spans x
+	// in [0, 10) but is translated by 100, so is visible.
+	parallaxed := &fakeRecorder{box: geom.Box{Max: geom.Pt3(10, 10, 10)}}
+	offscreen := &fakeRecorder{box: geom.Box{Max: geom.Pt3(10, 10, 10)}}
+	onscreen := &fakeRecorder{box: geom.Box{Min: geom.Pt3(200, 0, 0), Max: geom.Pt3(210, 10, 10)}}
+	d := &DrawDAG{
+		ChunkSize: 16,
+		Child: MakeContainer(
+			&Parallax{CameraID: "camera", Factor: 0.5, Child: parallaxed},
+			offscreen,
+			onscreen,
+		),
+	}
+	cam := &Camera{ID: "camera", Child: d, Zoom: 1}
+	g := &Game{
+		Root:       &DrawDFS{Child: cam},
+		ScreenSize: image.Pt(320, 240),
+		Projection: geom.ElevationProjection{},
+	}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v", err)
+	}
+	cam.Centre = image.Pt(200, 0)
+
+	screen := ebiten.NewImage(320, 240)
+	defer screen.Dispose()
+	g.Root.Draw(screen, &ebiten.DrawImageOptions{})
+
+	tests := []struct {
+		name string
+		rec  *fakeRecorder
+		want []geom.Float2
+	}{
+		{"parallaxed", parallaxed, []geom.Float2{{X: 60, Y: 120}}},
+		{"offscreen", offscreen, nil},
+		{"onscreen", onscreen, []geom.Float2{{X: -40, Y: 120}}}, // (0, 0) is off screen, but the box isn't
+	}
+	for _, test := range tests {
+		if diff := cmp.Diff(test.rec.drawn, test.want); diff != "" {
+			t.Errorf("%s: drawn positions diff:\n%s", test.name, diff)
+		}
+	}
+}
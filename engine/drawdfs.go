@@ -46,32 +46,69 @@ type DrawDFS struct {
 // Draw draws all descendant components (that are not managed by some other
 // DrawManager) in a pre-order traversal.
 func (d *DrawDFS) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
-	stack := []ebiten.DrawImageOptions{*opts}
-	d.game.Query(d, DrawerType,
+	drawDFS(d.game, d, d, screen, opts)
+}
+
+// drawDFS draws root and its descendants (that are not managed by some other
+// DrawManager) in a pre-order traversal. manager, if encountered, is neither
+// drawn nor skipped, and its transform is not applied (it is assumed to be
+// part of opts already, if needed).
+func drawDFS(g *Game, manager, root any, screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
+	walkDrawers(g, manager, root, opts, func(d Drawer, st *drawState) {
+		d.Draw(screen, &st.opts)
+	})
+}
+
+// drawState is the state accumulated along the path to a drawer.
+type drawState struct {
+	opts  ebiten.DrawImageOptions
+	layer int
+}
+
+// walkDrawers calls visit for root and its descendants that are Drawers (and
+// not managed by some other DrawManager) in a pre-order traversal, together
+// with the cumulative opts and the layer (see Layerer). Hidden components and
+// their descendants are not visited. manager is treated as in drawDFS.
+func walkDrawers(g *Game, manager, root any, opts *ebiten.DrawImageOptions, visit func(Drawer, *drawState)) {
+	stack := []drawState{{opts: *opts}}
+	g.Query(root, DrawerType,
 		// visitPre
 		func(x any) error {
 			if h, ok := x.(Hider); ok && h.Hidden() {
 				return Skip
 			}
-			opts := stack[len(stack)-1]
-			if tf, ok := x.(Transformer); ok {
-				opts = concatOpts(tf.Transform(), opts)
-				stack = append(stack, opts)
-			}
-			if x == d { // neither draw nor skip d itself
+			if x == manager { // neither draw nor skip the manager itself
 				return nil
 			}
+			st := stack[len(stack)-1]
+			tf, isTf := x.(Transformer)
+			if isTf {
+				st.opts = concatOpts(tf.Transform(), st.opts)
+			}
+			ly, isLy := x.(Layerer)
+			if isLy {
+				st.layer = ly.DrawLayer()
+			}
+			_, isDM := x.(DrawManager)
+			if (isTf || isLy) && !isDM { // Skip also skips visitPost
+				stack = append(stack, st)
+			}
 			if dr, ok := x.(Drawer); ok {
-				dr.Draw(screen, &opts)
+				visit(dr, &st)
 			}
-			if _, isDM := x.(DrawManager); isDM {
+			if isDM {
 				return Skip
 			}
 			return nil
 		},
 		// visitPost
 		func(x any) error {
-			if _, ok := x.(Transformer); ok {
+			if x == manager {
+				return nil
+			}
+			_, isTf := x.(Transformer)
+			_, isLy := x.(Layerer)
+			if isTf || isLy {
 				stack = stack[:len(stack)-1]
 			}
 			return nil
@@ -0,0 +1,200 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"encoding/gob"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+var (
+	_ interface {
+		Drawer
+		DrawManager
+		Prepper
+		Scanner
+	} = &DrawSorted{}
+
+	_ interface {
+		Layerer
+		Scanner
+	} = &Layer{}
+)
+
+func init() {
+	gob.Register(&DrawSorted{})
+	gob.Register(&Layer{})
+}
+
+// SortKey values choose how DrawSorted orders drawers within a layer.
+type SortKey int
+
+const (
+	SortNone SortKey = iota // only sort by layer
+	SortByY                 // sort by the bottom (Max.Y) of the bounding box
+	SortByZ                 // sort by the front (Max.Z) of the bounding box
+)
+
+// DrawSorted is a DrawManager that draws Drawer descendants sorted by layer
+// (see Layerer), and within each layer, by SortBy. Drawers that aren't
+// BoundingBoxers are drawn before those that are within each layer. Ties are
+// broken by the order DrawDFS would use, so the sort is stable.
+//
+// DrawSorted is much cheaper than DrawDAG, but is only suited to scenes where
+// a single key determines draw order (such as plain 2D or top-down scenes).
+// Each Draw starts from the previous order, so when only a few keys change
+// between frames, re-sorting is fast.
+type DrawSorted struct {
+	Child any
+	Hides
+	SortBy SortKey
+
+	game  *Game
+	order []Drawer // order used by the previous Draw
+	items []sortedItem
+	index map[Drawer]int // drawer -> index into items
+}
+
+// sortedItem is a drawer being sorted by DrawSorted.
+type sortedItem struct {
+	drawer Drawer
+	state  drawState
+	dfs    int // position in the depth-first traversal
+	key    int
+}
+
+// less reports if a should be drawn before b.
+func (a *sortedItem) less(b *sortedItem) bool {
+	if a.state.layer != b.state.layer {
+		return a.state.layer < b.state.layer
+	}
+	if a.key != b.key {
+		return a.key < b.key
+	}
+	return a.dfs < b.dfs
+}
+
+// Draw draws all descendant components (that are not managed by some other
+// DrawManager) in sorted order.
+func (d *DrawSorted) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
+	for _, it := range d.sorted(opts) {
+		it.drawer.Draw(screen, &it.state.opts)
+	}
+}
+
+// ManagesDrawingSubcomponents is present so DrawSorted is recognised as a
+// DrawManager.
+func (DrawSorted) ManagesDrawingSubcomponents() {}
+
+// Prepare saves a reference to g.
+func (d *DrawSorted) Prepare(g *Game) error {
+	d.game = g
+	d.order = nil
+	return nil
+}
+
+// Scan visits d.Child.
+func (d *DrawSorted) Scan(visit VisitFunc) error {
+	return visit(d.Child)
+}
+
+func (d *DrawSorted) String() string { return "DrawSorted" }
+
+// key returns the sort key for x within its layer.
+func (d *DrawSorted) key(x Drawer) int {
+	bb, ok := x.(BoundingBoxer)
+	if !ok {
+		return math.MinInt
+	}
+	switch d.SortBy {
+	case SortByY:
+		return bb.BoundingBox().Max.Y
+	case SortByZ:
+		return bb.BoundingBox().Max.Z
+	}
+	return 0
+}
+
+// sorted finds the drawers to draw, and sorts them starting from the previous
+// order.
+func (d *DrawSorted) sorted(opts *ebiten.DrawImageOptions) []sortedItem {
+	if d.index == nil {
+		d.index = make(map[Drawer]int)
+	}
+	for x := range d.index {
+		delete(d.index, x)
+	}
+	found := d.items[:0]
+	walkDrawers(d.game, d, d, opts, func(x Drawer, st *drawState) {
+		d.index[x] = len(found)
+		found = append(found, sortedItem{
+			drawer: x,
+			state:  *st,
+			dfs:    len(found),
+			key:    d.key(x),
+		})
+	})
+
+	// Arrange drawers that were drawn last time in their previous order,
+	// followed by new drawers in traversal order.
+	list := make([]sortedItem, 0, len(found))
+	for _, x := range d.order {
+		i, ok := d.index[x]
+		if !ok {
+			continue
+		}
+		list = append(list, found[i])
+		delete(d.index, x)
+	}
+	for _, it := range found {
+		if _, isNew := d.index[it.drawer]; isNew {
+			list = append(list, it)
+		}
+	}
+
+	// Insertion sort: linear when the list is nearly sorted already.
+	for i := 1; i < len(list); i++ {
+		for j := i; j > 0 && list[j].less(&list[j-1]); j-- {
+			list[j], list[j-1] = list[j-1], list[j]
+		}
+	}
+
+	d.items = found
+	d.order = d.order[:0]
+	for _, it := range list {
+		d.order = append(d.order, it.drawer)
+	}
+	return list
+}
+
+// Layer sets the draw layer for its descendants (see Layerer and DrawSorted).
+type Layer struct {
+	Child any
+	Z     int
+}
+
+// DrawLayer returns l.Z.
+func (l *Layer) DrawLayer() int { return l.Z }
+
+// Scan visits l.Child.
+func (l *Layer) Scan(visit VisitFunc) error {
+	return visit(l.Child)
+}
+
+func (l *Layer) String() string { return "Layer" }
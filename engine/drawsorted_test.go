@@ -0,0 +1,77 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/DrJosh9000/ichigo/geom"
+	"github.com/google/go-cmp/cmp"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type fakeSortee struct {
+	Hides
+	name string
+	y    int
+	log  *[]string
+}
+
+func (f *fakeSortee) BoundingBox() geom.Box {
+	return geom.Box{Min: geom.Pt3(0, f.y-1, 0), Max: geom.Pt3(1, f.y, 1)}
+}
+
+func (f *fakeSortee) Draw(*ebiten.Image, *ebiten.DrawImageOptions) {
+	*f.log = append(*f.log, f.name)
+}
+
+func TestDrawSorted(t *testing.T) {
+	var log []string
+	a := &fakeSortee{name: "a", y: 30, log: &log}
+	b := &fakeSortee{name: "b", y: 10, log: &log}
+	c := &fakeSortee{name: "c", y: 20, log: &log}
+	tie := &fakeSortee{name: "tie", y: 20, log: &log}
+	top := &fakeSortee{name: "top", y: 0, log: &log}
+	hidden := &fakeSortee{name: "hidden", y: 5, log: &log}
+	hidden.Hide()
+	ds := &DrawSorted{
+		SortBy: SortByY,
+		Child: MakeContainer(
+			a, b, c, tie, hidden,
+			&Layer{Z: 1, Child: top},
+		),
+	}
+	g := &Game{Root: ds}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v, want nil", err)
+	}
+
+	ds.Draw(nil, &ebiten.DrawImageOptions{})
+	want := []string{"b", "c", "tie", "a", "top"}
+	if diff := cmp.Diff(log, want); diff != "" {
+		t.Errorf("first draw order diff (-got +want):\n%s", diff)
+	}
+
+	// Move a to the top of the bottom layer, and c below tie.
+	log = nil
+	a.y, c.y = 0, 25
+	ds.Draw(nil, &ebiten.DrawImageOptions{})
+	want = []string{"a", "b", "tie", "c", "top"}
+	if diff := cmp.Diff(log, want); diff != "" {
+		t.Errorf("second draw order diff (-got +want):\n%s", diff)
+	}
+}
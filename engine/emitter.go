@@ -0,0 +1,388 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/DrJosh9000/ichigo/geom"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Ensure Emitter satisfies interfaces.
+var _ interface {
+	Disabler
+	DrawBoxer
+	Hider
+	Identifier
+	Prepper
+	Scanner
+	Updater
+} = &Emitter{}
+
+// Ensure the splines can be used as curves.
+var (
+	_ Curve = &geom.LinearSpline{}
+	_ Curve = &geom.CubicSpline{}
+)
+
+func init() {
+	gob.Register(&Emitter{})
+	gob.Register(&geom.LinearSpline{})
+	gob.Register(&geom.CubicSpline{})
+}
+
+// Curve maps a value to another value. geom.LinearSpline and geom.CubicSpline
+// are both curves.
+type Curve interface {
+	Interpolate(float64) float64
+}
+
+// ParticleCurves are curves applied to each particle over its lifetime. Each
+// curve is evaluated at the particle's age as a fraction of its lifetime (0 at
+// birth, 1 at death). A nil curve means a constant 1.
+type ParticleCurves struct {
+	Speed                   Curve // multiplies the particle velocity
+	Scale                   Curve // scales the particle image about its centre
+	Red, Green, Blue, Alpha Curve // scale the colour channels
+}
+
+// ParticleBurst spawns Count particles at once, Tick ticks after the emitter
+// started (or after the start of each Period, if the emitter has one).
+type ParticleBurst struct {
+	Tick  int
+	Count int
+}
+
+// Emitter is a particle system. Particles are kept in a fixed-size pool, and
+// are drawn by the emitter itself, so the whole system is a single DrawBoxer
+// within a DrawDAG. Positions and velocities are in voxels (and voxels per
+// tick).
+type Emitter struct {
+	ID
+	Disables
+	Hides
+	Pos    geom.Int3 // where particles spawn
+	Spread geom.Box  // particles spawn at a random point within Spread.Add(Pos)
+	Sheet  Sheet     // particle images
+	Anim   string    // key of AnimDef in Sheet used for particles; "" means cell 0
+
+	MaxParticles   int             // size of the particle pool
+	Rate           float64         // particles spawned per tick
+	Bursts         []ParticleBurst // scheduled bursts
+	Period         int             // if nonzero, Bursts repeat with this period
+	Lifetime       int             // ticks each particle lives
+	LifetimeJitter int             // lifetimes vary by up to ± this much
+	Velocity       geom.Float3     // initial particle velocity
+	VelocityJitter geom.Float3     // initial velocities vary by up to ± this much
+	Wander         geom.Float3     // velocity changes by up to ± this much per tick
+	Curves         ParticleCurves
+
+	// If CollisionDomain is not empty, particles collide with Colliders within
+	// that component. Colliding particles die if DieOnCollide is set, or
+	// otherwise bounce (their velocity is reversed and scaled by Restitution).
+	CollisionDomain string
+	Bounds          geom.Box // particle bounds, relative to particle position
+	DieOnCollide    bool
+	Restitution     float64
+
+	game      *Game
+	anim      *AnimDef
+	particles []particle // live particles are particles[:live]
+	live      int
+	ticks     int
+	spawn     float64 // fractional particles carried over from Rate
+}
+
+// particle is the state of a single particle.
+type particle struct {
+	pos, vel  geom.Float3
+	age, life int
+}
+
+// Burst spawns n particles immediately (limited by the space in the pool).
+func (e *Emitter) Burst(n int) {
+	for ; n > 0 && e.live < len(e.particles); n-- {
+		e.spawnOne()
+	}
+}
+
+// BoundingBox returns a box containing all live particles, or the emitter
+// position if there are none.
+func (e *Emitter) BoundingBox() geom.Box {
+	if e.live == 0 {
+		return geom.Box{Min: e.Pos, Max: e.Pos}
+	}
+	b := e.particles[0].box(e.Bounds)
+	for i := range e.particles[1:e.live] {
+		b = b.Union(e.particles[i+1].box(e.Bounds))
+	}
+	return b
+}
+
+// Draw draws all the live particles.
+func (e *Emitter) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
+	cx, cy := float64(e.Sheet.CellSize.X)/2, float64(e.Sheet.CellSize.Y)/2
+	for i := range e.particles[:e.live] {
+		p := &e.particles[i]
+		t := p.t()
+		s := curveAt(e.Curves.Scale, t)
+		if s <= 0 {
+			continue
+		}
+		var o ebiten.DrawImageOptions
+		o.GeoM.Translate(-cx, -cy)
+		o.GeoM.Scale(s, s)
+		o.GeoM.Translate(geom.CFloat(geom.Project(e.game.Projection, p.voxel())))
+		o.GeoM.Concat(opts.GeoM)
+		o.ColorM.Scale(
+			curveAt(e.Curves.Red, t),
+			curveAt(e.Curves.Green, t),
+			curveAt(e.Curves.Blue, t),
+			curveAt(e.Curves.Alpha, t),
+		)
+		o.ColorM.Concat(opts.ColorM)
+		o.CompositeMode = opts.CompositeMode
+		o.Filter = opts.Filter
+		screen.DrawImage(e.Sheet.SubImage(e.cell(p.age)), &o)
+	}
+}
+
+// Live returns the number of live particles.
+func (e *Emitter) Live() int { return e.live }
+
+// Prepare prepares the curves, and allocates the particle pool.
+func (e *Emitter) Prepare(g *Game) error {
+	e.game = g
+	if e.MaxParticles < 0 {
+		return fmt.Errorf("negative MaxParticles %d", e.MaxParticles)
+	}
+	if e.Lifetime <= 0 {
+		return errors.New("particle lifetime must be positive")
+	}
+	e.anim = nil
+	if e.Anim != "" {
+		e.anim = e.Sheet.AnimDefs[e.Anim]
+		if e.anim == nil {
+			return fmt.Errorf("anim def %q not found in sheet", e.Anim)
+		}
+	}
+	for _, c := range []Curve{
+		e.Curves.Speed,
+		e.Curves.Scale,
+		e.Curves.Red,
+		e.Curves.Green,
+		e.Curves.Blue,
+		e.Curves.Alpha,
+	} {
+		p, ok := c.(interface{ Prepare() error })
+		if !ok {
+			continue
+		}
+		if err := p.Prepare(); err != nil {
+			return fmt.Errorf("preparing curve: %w", err)
+		}
+	}
+	if len(e.particles) != e.MaxParticles {
+		e.particles = make([]particle, e.MaxParticles)
+		e.live = 0
+	}
+	return nil
+}
+
+// Scan visits &e.Sheet.
+func (e *Emitter) Scan(visit VisitFunc) error {
+	return visit(&e.Sheet)
+}
+
+func (e *Emitter) String() string {
+	return fmt.Sprintf("Emitter@%v", e.Pos)
+}
+
+// Update ages and moves the live particles, and spawns new ones.
+func (e *Emitter) Update() error {
+	if e.Disabled() {
+		return nil
+	}
+
+	var colliders []Collider
+	if e.CollisionDomain != "" {
+		colliders = collidersIn(e.game, e.CollisionDomain)
+	}
+
+	for i := 0; i < e.live; {
+		p := &e.particles[i]
+		p.age++
+		if p.age >= p.life || !e.move(p, colliders) {
+			// Swap the dead particle with the last live one.
+			e.live--
+			e.particles[i], e.particles[e.live] = e.particles[e.live], e.particles[i]
+			continue
+		}
+		i++
+	}
+
+	// Scheduled bursts
+	tick := e.ticks
+	if e.Period > 0 {
+		tick %= e.Period
+	}
+	for _, b := range e.Bursts {
+		if b.Tick == tick {
+			e.Burst(b.Count)
+		}
+	}
+	e.ticks++
+
+	// Continuous emission
+	e.spawn += e.Rate
+	n := int(e.spawn)
+	e.spawn -= float64(n)
+	e.Burst(n)
+	return nil
+}
+
+// cell returns the sheet cell for a particle of the given age.
+func (e *Emitter) cell(age int) int {
+	if e.anim == nil || len(e.anim.Steps) == 0 {
+		return 0
+	}
+	total := 0
+	for _, s := range e.anim.Steps {
+		total += s.Duration
+	}
+	if total <= 0 {
+		return e.anim.Steps[0].Cell
+	}
+	if !e.anim.OneShot {
+		age %= total
+	}
+	for _, s := range e.anim.Steps {
+		if age < s.Duration {
+			return s.Cell
+		}
+		age -= s.Duration
+	}
+	return e.anim.Steps[len(e.anim.Steps)-1].Cell
+}
+
+// collides reports if a particle at p would collide with any of cs.
+func (e *Emitter) collides(p geom.Int3, cs []Collider) bool {
+	b := e.Bounds.Add(p)
+	for _, c := range cs {
+		if c.CollidesWith(b) {
+			return true
+		}
+	}
+	return false
+}
+
+// move moves a particle one tick along its velocity, handling collisions. It
+// returns false if the particle died.
+func (e *Emitter) move(p *particle, cs []Collider) bool {
+	p.vel = p.vel.Add(jitter(e.Wander))
+	v := p.vel.Mul(curveAt(e.Curves.Speed, p.t()))
+	if len(cs) == 0 {
+		p.pos = p.pos.Add(v)
+		return true
+	}
+	// Move one axis at a time, so that bounces reflect the correct component.
+	axes := [3]struct {
+		pos, vel *float64
+		d        float64
+	}{
+		{&p.pos.X, &p.vel.X, v.X},
+		{&p.pos.Y, &p.vel.Y, v.Y},
+		{&p.pos.Z, &p.vel.Z, v.Z},
+	}
+	for _, a := range axes {
+		from := p.voxel()
+		*a.pos += a.d
+		if p.voxel() == from || !e.collides(p.voxel(), cs) {
+			continue
+		}
+		if e.DieOnCollide {
+			return false
+		}
+		*a.pos -= a.d
+		*a.vel *= -e.Restitution
+	}
+	return true
+}
+
+// spawnOne spawns a single particle. The pool must have space.
+func (e *Emitter) spawnOne() {
+	area := e.Spread.Add(e.Pos)
+	size := area.Size()
+	life := e.Lifetime
+	if e.LifetimeJitter > 0 {
+		life += rand.Intn(2*e.LifetimeJitter+1) - e.LifetimeJitter
+	}
+	if life < 1 {
+		life = 1
+	}
+	e.particles[e.live] = particle{
+		pos: geom.Float3{
+			X: float64(area.Min.X) + rand.Float64()*float64(size.X),
+			Y: float64(area.Min.Y) + rand.Float64()*float64(size.Y),
+			Z: float64(area.Min.Z) + rand.Float64()*float64(size.Z),
+		},
+		vel:  e.Velocity.Add(jitter(e.VelocityJitter)),
+		life: life,
+	}
+	e.live++
+}
+
+// box returns the bounds of the particle.
+func (p *particle) box(bounds geom.Box) geom.Box {
+	return bounds.Add(p.voxel())
+}
+
+// t returns the age of the particle as a fraction of its lifetime.
+func (p *particle) t() float64 {
+	return float64(p.age) / float64(p.life)
+}
+
+// voxel returns the position of the particle, rounded to the nearest voxel.
+func (p *particle) voxel() geom.Int3 {
+	return geom.Int3{
+		X: int(math.Round(p.pos.X)),
+		Y: int(math.Round(p.pos.Y)),
+		Z: int(math.Round(p.pos.Z)),
+	}
+}
+
+// curveAt evaluates c at t, or returns 1 if c is nil.
+func curveAt(c Curve, t float64) float64 {
+	if c == nil {
+		return 1
+	}
+	return c.Interpolate(t)
+}
+
+// jitter returns a random vector with each component in [-j, j].
+func jitter(j geom.Float3) geom.Float3 {
+	return geom.Float3{
+		X: (2*rand.Float64() - 1) * j.X,
+		Y: (2*rand.Float64() - 1) * j.Y,
+		Z: (2*rand.Float64() - 1) * j.Z,
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/DrJosh9000/ichigo/geom"
+)
+
+func TestEmitterLifecycle(t *testing.T) {
+	e := &Emitter{
+		MaxParticles: 4,
+		Rate:         0.5,
+		Bursts:       []ParticleBurst{{Tick: 2, Count: 3}},
+		Lifetime:     4,
+		Velocity:     geom.Float3{X: 1},
+		Curves: ParticleCurves{
+			Speed: &geom.LinearSpline{Points: []geom.Float2{{X: 0, Y: 1}, {X: 1, Y: 0}}},
+		},
+	}
+	if err := e.Prepare(&Game{}); err != nil {
+		t.Fatalf("e.Prepare(g) = %v, want nil", err)
+	}
+
+	// Rate spawns one particle on odd ticks, the burst adds 3 on tick 2, and
+	// particles die 4 ticks after spawning. The pool has no room on tick 3.
+	want := []int{0, 1, 4, 4, 4, 4, 1}
+	for tick, w := range want {
+		if err := e.Update(); err != nil {
+			t.Fatalf("e.Update() = %v, want nil", err)
+		}
+		if got := e.Live(); got != w {
+			t.Errorf("after tick %d: e.Live() = %d, want %d", tick, got, w)
+		}
+	}
+}
+
+func TestEmitterCell(t *testing.T) {
+	e := &Emitter{
+		Sheet: Sheet{
+			AnimDefs: map[string]*AnimDef{
+				"pop": {
+					Steps: []AnimStep{
+						{Cell: 3, Duration: 2},
+						{Cell: 4, Duration: 1},
+					},
+					OneShot: true,
+				},
+			},
+		},
+		Anim:     "pop",
+		Lifetime: 1,
+	}
+	if err := e.Prepare(&Game{}); err != nil {
+		t.Fatalf("e.Prepare(g) = %v, want nil", err)
+	}
+	for age, want := range []int{3, 3, 4, 4, 4} {
+		if got := e.cell(age); got != want {
+			t.Errorf("e.cell(%d) = %d, want %d", age, got, want)
+		}
+	}
+}
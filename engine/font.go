@@ -0,0 +1,161 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"encoding/gob"
+	"image"
+	"strings"
+	"unicode/utf8"
+)
+
+var _ interface {
+	Identifier
+	Scanner
+} = &BitmapFont{}
+
+func init() {
+	gob.Register(&BitmapFont{})
+}
+
+// Glyph describes how to draw one character of a BitmapFont.
+type Glyph struct {
+	Cell    int         // cell in the font sheet
+	Offset  image.Point // drawing offset from the pen position
+	Advance int         // how far to move the pen; zero means Sheet.CellSize.X
+}
+
+// BitmapFont is a font where each character is drawn from a cell of a Sheet.
+// Text components refer to a BitmapFont by its ID.
+type BitmapFont struct {
+	ID
+	Sheet  Sheet
+	Glyphs map[rune]Glyph
+
+	// Runes without an entry in Glyphs use cell (rune - FirstRune), if that
+	// is a cell in the sheet. This suits fonts laid out in order (e.g. ASCII
+	// starting at ' ').
+	FirstRune rune
+
+	// Fallback is drawn in place of runes that have no glyph.
+	Fallback rune
+
+	LineHeight int            // zero means Sheet.CellSize.Y
+	Kerning    map[string]int // extra advance between pairs of runes, e.g. "AV": -1
+}
+
+// Scan visits &f.Sheet.
+func (f *BitmapFont) Scan(visit VisitFunc) error {
+	return visit(&f.Sheet)
+}
+
+func (f *BitmapFont) String() string { return "BitmapFont" }
+
+// Glyph returns the glyph for a rune, or the fallback glyph.
+func (f *BitmapFont) Glyph(r rune) (Glyph, bool) {
+	if g, ok := f.glyph(r); ok {
+		return g, true
+	}
+	return f.glyph(f.Fallback)
+}
+
+func (f *BitmapFont) glyph(r rune) (Glyph, bool) {
+	if g, ok := f.Glyphs[r]; ok {
+		if g.Advance == 0 {
+			g.Advance = f.Sheet.CellSize.X
+		}
+		return g, true
+	}
+	cell := int(r - f.FirstRune)
+	// The number of cells is unknown (zero) until the sheet is prepared.
+	if cell < 0 || (f.Sheet.n > 0 && cell >= f.Sheet.n) {
+		return Glyph{}, false
+	}
+	return Glyph{
+		Cell:    cell,
+		Advance: f.Sheet.CellSize.X,
+	}, true
+}
+
+// Kern returns the kerning adjustment between a pair of runes.
+func (f *BitmapFont) Kern(prev, r rune) int {
+	if len(f.Kerning) == 0 {
+		return 0
+	}
+	return f.Kerning[string([]rune{prev, r})]
+}
+
+// LineSpacing returns the distance between lines of text.
+func (f *BitmapFont) LineSpacing() int {
+	if f.LineHeight != 0 {
+		return f.LineHeight
+	}
+	return f.Sheet.CellSize.Y
+}
+
+// Measure returns the width of a single line of text.
+func (f *BitmapFont) Measure(s string) int {
+	w := 0
+	prev := rune(-1)
+	for _, r := range s {
+		if prev >= 0 {
+			w += f.Kern(prev, r)
+		}
+		if g, ok := f.Glyph(r); ok {
+			w += g.Advance
+		}
+		prev = r
+	}
+	return w
+}
+
+// Wrap splits text into lines no wider than width (if possible), breaking at
+// spaces and newlines. Words wider than width are put on a line of their own.
+// If width <= 0, text is only split at newlines.
+func (f *BitmapFont) Wrap(text string, width int) []string {
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		if width <= 0 {
+			lines = append(lines, para)
+			continue
+		}
+		line := ""
+		for _, word := range strings.Fields(para) {
+			if line == "" {
+				line = word
+				continue
+			}
+			if f.Measure(line+" "+word) <= width {
+				line += " " + word
+				continue
+			}
+			lines = append(lines, line)
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// runeCount is the number of runes in all the lines.
+func runeCount(lines []string) int {
+	n := 0
+	for _, l := range lines {
+		n += utf8.RuneCountInString(l)
+	}
+	return n
+}
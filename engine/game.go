@@ -23,6 +23,7 @@ import (
 	"image"
 	"io/fs"
 	"log"
+	"math"
 	"reflect"
 	"sync"
 	"time"
@@ -56,7 +57,8 @@ type Game struct {
 	Hides
 	Projection geom.Projector
 	Root       Drawer
-	ScreenSize image.Point
+	ScreenSize image.Point // logical screen size (see Scaling)
+	Scaling    Scaling
 	VoxelScale geom.Float3
 
 	dbmu     sync.RWMutex
@@ -64,6 +66,9 @@ type Game struct {
 	byAB     map[abKey]*Container   // paths matching interface
 	parent   map[any]any 	        // parent[x] is parent of x
 	children map[any]*Container     // children[x] are children of x
+
+	layout    screenLayout
+	offscreen *ebiten.Image // for scaling policies that draw indirectly
 }
 
 // Draw draws everything.
@@ -71,12 +76,53 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	if g.Hidden() {
 		return
 	}
-	g.Root.Draw(screen, &ebiten.DrawImageOptions{})
+	if g.layout.direct() {
+		g.Root.Draw(screen, &ebiten.DrawImageOptions{})
+		return
+	}
+
+	// Draw the logical screen, then scale it onto the real screen.
+	sz := g.layout.logical
+	if g.offscreen != nil {
+		if w, h := g.offscreen.Size(); w != sz.X || h != sz.Y {
+			g.offscreen.Dispose()
+			g.offscreen = nil
+		}
+	}
+	if g.offscreen == nil {
+		g.offscreen = ebiten.NewImage(sz.X, sz.Y)
+	}
+	g.offscreen.Clear()
+	g.Root.Draw(g.offscreen, &ebiten.DrawImageOptions{})
+
+	var opts ebiten.DrawImageOptions
+	opts.GeoM.Scale(g.layout.scale, g.layout.scale)
+	opts.GeoM.Translate(geom.CFloat(g.layout.offset))
+	opts.Filter = ebiten.FilterNearest
+	screen.DrawImage(g.offscreen, &opts)
 }
 
-// Layout returns the configured screen width/height.
+// Layout applies the Scaling policy, and returns the resulting screen
+// width/height.
 func (g *Game) Layout(outsideWidth, outsideHeight int) (w, h int) {
-	return g.ScreenSize.X, g.ScreenSize.Y
+	outside := image.Pt(outsideWidth, outsideHeight)
+	if g.Scaling == ScaleInteger || g.Scaling == ScaleFill {
+		// Use device pixels, so that scaling by whole numbers is exact.
+		s := ebiten.DeviceScaleFactor()
+		outside = image.Pt(int(float64(outsideWidth)*s), int(float64(outsideHeight)*s))
+	}
+	g.layout = computeLayout(g.Scaling, g.ScreenSize, outside)
+	return g.layout.outside.X, g.layout.outside.Y
+}
+
+// LogicalScreenSize returns the size of the screen that components draw onto.
+// This is ScreenSize, unless the Scaling policy is ScaleExpand (in which case
+// it could be larger).
+func (g *Game) LogicalScreenSize() image.Point {
+	if g.layout.logical == (image.Point{}) {
+		return g.ScreenSize
+	}
+	return g.layout.logical
 }
 
 // Update updates everything. Subcomponents are updated before parent
@@ -151,6 +197,16 @@ func (g *Game) PathUnregister(component any) {
 	}
 }
 
+// NotifyMoved tells every MoveListener on the path to the component that
+// component has moved (see MoveNotifier).
+func (g *Game) NotifyMoved(component any) {
+	for _, p := range g.ReversePath(component) {
+		if l, ok := p.(MoveListener); ok {
+			l.Moved(component)
+		}
+	}
+}
+
 // Path returns a slice with the path of components to reach component from g
 // (including g and component).
 func (g *Game) Path(component any) []any {
@@ -420,6 +476,10 @@ func (g *Game) unregisterOne(component any) {
 	if id, ok := component.(Identifier); ok && id.Ident() != "" {
 		delete(g.byID, id.Ident())
 	}
+
+	if u, ok := component.(Unloader); ok {
+		u.Unload()
+	}
 }
 
 func (g *Game) String() string { return "Game" }
@@ -449,6 +509,32 @@ func concatOpts(a, b ebiten.DrawImageOptions) ebiten.DrawImageOptions {
 	return a
 }
 
+// visibleRect returns a rectangle containing everything that would be drawn
+// within the screen bounds using geoM, in the coordinates before geoM is
+// applied. It returns false if geoM can't be inverted (e.g. zero scale).
+func visibleRect(screen image.Rectangle, geoM ebiten.GeoM) (image.Rectangle, bool) {
+	if !geoM.IsInvertible() {
+		return image.Rectangle{}, false
+	}
+	geoM.Invert()
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range []image.Point{
+		screen.Min,
+		{screen.Max.X, screen.Min.Y},
+		{screen.Min.X, screen.Max.Y},
+		screen.Max,
+	} {
+		x, y := geoM.Apply(float64(p.X), float64(p.Y))
+		minX, minY = math.Min(minX, x), math.Min(minY, y)
+		maxX, maxY = math.Max(maxX, x), math.Max(maxY, y)
+	}
+	return image.Rect(
+		int(math.Floor(minX)), int(math.Floor(minY)),
+		int(math.Ceil(maxX)), int(math.Ceil(maxY)),
+	), true
+}
+
 // VisitFunc callbacks are either provided or called by various Game functions.
 // For example, Query takes two VisitFuncs that are called for each result, and
 // Scan is given a VisitFunc that should be called with each component. For
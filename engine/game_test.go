@@ -16,7 +16,12 @@ limitations under the License.
 
 package engine
 
-import "testing"
+import (
+	"image"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
 
 func TestGameLoadAndPrepare(t *testing.T) {
 	g := &Game{
@@ -26,3 +31,61 @@ func TestGameLoadAndPrepare(t *testing.T) {
 		t.Errorf("LoadAndPrepare(nil) = %v, want nil", err)
 	}
 }
+
+func TestVisibleRect(t *testing.T) {
+	screen := image.Rect(0, 0, 320, 240)
+
+	var scroll ebiten.GeoM
+	scroll.Translate(-100, -50)
+
+	var zoom ebiten.GeoM
+	zoom.Translate(-160, -120)
+	zoom.Scale(2, 2)
+	zoom.Translate(160, 120)
+
+	var flip ebiten.GeoM
+	flip.Scale(-1, 1)
+
+	var zero ebiten.GeoM
+	zero.Scale(0, 0)
+
+	tests := []struct {
+		name   string
+		geoM   ebiten.GeoM
+		want   image.Rectangle
+		wantOK bool
+	}{
+		{"identity", ebiten.GeoM{}, screen, true},
+		{"scroll", scroll, image.Rect(100, 50, 420, 290), true},
+		{"zoom", zoom, image.Rect(80, 60, 240, 180), true},
+		{"flip", flip, image.Rect(-320, 0, 0, 240), true},
+		{"zero", zero, image.Rectangle{}, false},
+	}
+	for _, test := range tests {
+		got, ok := visibleRect(screen, test.geoM)
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("%s: visibleRect(screen, geoM) = (%v, %v), want (%v, %v)", test.name, got, ok, test.want, test.wantOK)
+		}
+	}
+}
+
+type fakeUnloader struct{ unloaded bool }
+
+func (u *fakeUnloader) Unload() { u.unloaded = true }
+
+func TestGameUnregisterUnloads(t *testing.T) {
+	u := &fakeUnloader{}
+	g := &Game{
+		Root: &DrawSorted{Child: MakeContainer(u)},
+	}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v, want nil", err)
+	}
+	if u.unloaded {
+		t.Error("before Unregister: u.unloaded = true, want false")
+	}
+	g.Unregister(g.Root)
+	if !u.unloaded {
+		t.Error("after Unregister: u.unloaded = false, want true")
+	}
+}
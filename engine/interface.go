@@ -39,13 +39,21 @@ var (
 	DrawOrdererType    = reflect.TypeOf((*DrawOrderer)(nil)).Elem()
 	HiderType          = reflect.TypeOf((*Hider)(nil)).Elem()
 	IdentifierType     = reflect.TypeOf((*Identifier)(nil)).Elem()
+	LayererType        = reflect.TypeOf((*Layerer)(nil)).Elem()
+	LayouterType       = reflect.TypeOf((*Layouter)(nil)).Elem()
 	LoaderType         = reflect.TypeOf((*Loader)(nil)).Elem()
+	MoveListenerType   = reflect.TypeOf((*MoveListener)(nil)).Elem()
+	MoveNotifierType   = reflect.TypeOf((*MoveNotifier)(nil)).Elem()
 	PrepperType        = reflect.TypeOf((*Prepper)(nil)).Elem()
 	RegistrarType      = reflect.TypeOf((*Registrar)(nil)).Elem()
 	SaverType          = reflect.TypeOf((*Saver)(nil)).Elem()
 	ScannerType        = reflect.TypeOf((*Scanner)(nil)).Elem()
+	SnapshotterType    = reflect.TypeOf((*Snapshotter)(nil)).Elem()
 	TransformerType    = reflect.TypeOf((*Transformer)(nil)).Elem()
+	UnloaderType       = reflect.TypeOf((*Unloader)(nil)).Elem()
 	UpdaterType        = reflect.TypeOf((*Updater)(nil)).Elem()
+	XRayOccluderType   = reflect.TypeOf((*XRayOccluder)(nil)).Elem()
+	XRayTargetType     = reflect.TypeOf((*XRayTarget)(nil)).Elem()
 
 	// Behaviours lists all the behaviours that can be queried with Game.Query.
 	Behaviours = []reflect.Type{
@@ -59,13 +67,21 @@ var (
 		DrawOrdererType,
 		HiderType,
 		IdentifierType,
+		LayererType,
+		LayouterType,
 		LoaderType,
+		MoveListenerType,
+		MoveNotifierType,
 		PrepperType,
 		RegistrarType,
 		SaverType,
 		ScannerType,
+		SnapshotterType,
 		TransformerType,
+		UnloaderType,
 		UpdaterType,
+		XRayOccluderType,
+		XRayTargetType,
 	}
 )
 
@@ -133,12 +149,43 @@ type Identifier interface {
 	Ident() string
 }
 
+// Layerer components have an explicit draw layer (a Z-index). DrawSorted
+// draws lower layers first. Descendants inherit the layer of their nearest
+// Layerer ancestor.
+type Layerer interface {
+	DrawLayer() int
+}
+
+// Layouter components can be positioned by UI layouts. MinSize reports the
+// smallest size the component needs, and Layout tells the component the
+// rectangle (in screen coordinates) it has been given.
+type Layouter interface {
+	MinSize() image.Point
+	Layout(image.Rectangle)
+}
+
 // Loader components get the chance to load themselves. This happens
 // before preparation.
 type Loader interface {
 	Load(fs.FS) error
 }
 
+// MoveListener components are told when a descendant has moved (see
+// Game.NotifyMoved).
+type MoveListener interface {
+	Moved(component any)
+}
+
+// MoveNotifier components can promise that every change to their bounding box
+// is reported with Game.NotifyMoved (either by themselves or a descendant, such
+// as an Actor), so MoveListeners don't have to keep checking them for changes.
+// NotifiesMoves reports whether the promise is made; it is checked when the
+// component is registered.
+type MoveNotifier interface {
+	BoundingBoxer
+	NotifiesMoves() bool
+}
+
 // Prepper components can be prepared. It is called after the component
 // database has been populated but before the game is run. The component can
 // store the reference to game, if needed, and also query the component database.
@@ -168,6 +215,17 @@ type Scanner interface {
 	Scan(visit VisitFunc) error
 }
 
+// Snapshotter components have mutable state worth keeping in a save game.
+// Snapshot returns a gob-encodable value (register it with gob.Register)
+// holding that state, and Restore applies such a value to the component.
+// Components are matched up by their identifier, so it must be non-empty and
+// stable between runs.
+type Snapshotter interface {
+	Identifier
+	Snapshot() (any, error)
+	Restore(any) error
+}
+
 // Transformer components can provide draw options to apply to themselves and
 // any child components. The opts passed to Draw of a component c will be the
 // cumulative opts of all parents of c plus the value returned from c.Transform.
@@ -175,9 +233,29 @@ type Transformer interface {
 	Transform() ebiten.DrawImageOptions
 }
 
+// Unloader components are notified when they are unregistered, so they can
+// release resources that the garbage collector doesn't (such as images).
+type Unloader interface {
+	Unload()
+}
+
 // Updater components can update themselves. Update is called repeatedly. Each
 // component must call Update on any internal components not known to the engine
 // (i.e. not passed to Game.Register or returned from Scan).
 type Updater interface {
 	Update() error
 }
+
+// XRayOccluder components choose how they are drawn by DrawDAG when they
+// cover an XRayTarget in XRayFade mode. XRayAlpha returns the alpha to use;
+// zero means the target's setting, and 1 means never fade.
+type XRayOccluder interface {
+	XRayAlpha() float64
+}
+
+// XRayTarget components stay visible when they are covered by other drawers
+// within a DrawDAG. XRayConfig returns the settings to use; nil means off.
+type XRayTarget interface {
+	DrawBoxer
+	XRayConfig() *XRay
+}
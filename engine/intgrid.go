@@ -0,0 +1,81 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"encoding/gob"
+	"image"
+
+	"github.com/DrJosh9000/ichigo/geom"
+)
+
+var _ interface {
+	Identifier
+	Collider
+} = &IntGrid{}
+
+func init() {
+	gob.Register(&IntGrid{})
+}
+
+// IntGrid is a Collider made from a grid of integer values (for example, an
+// LDtk IntGrid layer). Cells collide according to their values.
+type IntGrid struct {
+	ID
+	Offset   image.Point  // world coordinates
+	CellSize image.Point  // world units
+	Size     image.Point  // grid size, in cells
+	Values   []int        // row-major; 0 means empty
+	Solid    map[int]bool // values that collide; if empty, all non-zero values
+}
+
+// CollidesWith reports if any solid cell overlaps the box.
+func (g *IntGrid) CollidesWith(b geom.Box) bool {
+	// Probe the grid at all cells overlapping the rect.
+	r := b.XY().Sub(g.Offset)
+	min := geom.CFloorDiv(r.Min, g.CellSize)
+	max := geom.CFloorDiv(r.Max.Sub(image.Pt(1, 1)), g.CellSize) // NB: fencepost
+
+	for j := min.Y; j <= max.Y; j++ {
+		for i := min.X; i <= max.X; i++ {
+			if g.solid(g.cell(image.Pt(i, j))) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ValueAt returns the value of the cell at the given world coordinate, or 0
+// if it is outside the grid.
+func (g *IntGrid) ValueAt(wc image.Point) int {
+	return g.cell(geom.CFloorDiv(wc.Sub(g.Offset), g.CellSize))
+}
+
+func (g *IntGrid) cell(p image.Point) int {
+	if !p.In(image.Rectangle{Max: g.Size}) {
+		return 0
+	}
+	return g.Values[p.Y*g.Size.X+p.X]
+}
+
+func (g *IntGrid) solid(v int) bool {
+	if len(g.Solid) == 0 {
+		return v != 0
+	}
+	return g.Solid[v]
+}
@@ -0,0 +1,394 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"log"
+	"path"
+
+	"github.com/DrJosh9000/ichigo/geom"
+)
+
+var _ interface {
+	Loader
+	scener
+} = &LDtkLevel{}
+
+func init() {
+	gob.Register(&LDtkLevel{})
+}
+
+var ldtkEntities = make(map[string]LDtkEntityFunc)
+
+// LDtkEntity describes an entity instance in an LDtk level.
+type LDtkEntity struct {
+	Identifier string
+	IID        string
+	Pos        image.Point // position of the pivot, in level coordinates
+	Size       image.Point
+	Grid       image.Point // position in the layer grid, in cells
+
+	// Fields holds the custom fields. Int fields are int, Float fields are
+	// float64, Bool fields are bool, String and enum fields are string, and
+	// anything else is as decoded by encoding/json.
+	Fields map[string]any
+}
+
+// LDtkEntityFunc creates a component from an LDtk entity instance.
+type LDtkEntityFunc func(LDtkEntity) (any, error)
+
+// RegisterLDtkEntity registers a constructor for entities with the given
+// identifier. Entities without a registered constructor are skipped. Like
+// gob.Register, it panics if the identifier is already registered.
+func RegisterLDtkEntity(identifier string, ctor LDtkEntityFunc) {
+	if _, exists := ldtkEntities[identifier]; exists {
+		panic(fmt.Sprintf("duplicate LDtk entity %q", identifier))
+	}
+	ldtkEntities[identifier] = ctor
+}
+
+// LDtkLevel loads one level (by identifier) from an LDtk project in the asset
+// FS. After Load, Scene is usable. See LoadLDtk.
+type LDtkLevel struct {
+	Path        string
+	Level       string
+	SolidValues []int
+
+	*Scene // not gob encoded
+}
+
+// ldtkLevelGob is what is gob-encoded for LDtkLevel.
+type ldtkLevelGob struct {
+	Path, Level string
+	SolidValues []int
+}
+
+// GobDecode decodes Path, Level, and SolidValues.
+func (l *LDtkLevel) GobDecode(b []byte) error {
+	var lg ldtkLevelGob
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&lg); err != nil {
+		return err
+	}
+	l.Path, l.Level, l.SolidValues = lg.Path, lg.Level, lg.SolidValues
+	return nil
+}
+
+// GobEncode encodes Path, Level, and SolidValues (but not Scene).
+func (l *LDtkLevel) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(ldtkLevelGob{
+		Path:        l.Path,
+		Level:       l.Level,
+		SolidValues: l.SolidValues,
+	})
+	return buf.Bytes(), err
+}
+
+// Load loads the project and builds the level.
+func (l *LDtkLevel) Load(assets fs.FS) error {
+	p, err := loadLDtkProject(assets, l.Path)
+	if err != nil {
+		return err
+	}
+	for _, lev := range p.Levels {
+		if lev.Identifier != l.Level {
+			continue
+		}
+		sc, err := p.buildLevel(lev, l.SolidValues)
+		if err != nil {
+			return fmt.Errorf("loading %s: %w", l.Path, err)
+		}
+		l.Scene = sc
+		return nil
+	}
+	return fmt.Errorf("loading %s: no level %q", l.Path, l.Level)
+}
+
+func (l *LDtkLevel) String() string { return "LDtkLevel{" + l.Path + ", " + l.Level + "}" }
+
+// LoadLDtk loads an LDtk project (JSON) from the asset FS, and builds a Scene
+// for each level. The ID of each Scene is the level identifier, and the
+// Bounds are the level size. Within each level, from bottom to top:
+//
+//   - IntGrid layers become IntGrids, using the given solid values for
+//     collisions (or all non-zero values, if there are none). Any auto-layer
+//     tiles become a (non-colliding) Tilemap.
+//   - Tiles and AutoLayer layers become (non-colliding) Tilemaps.
+//   - Entities are created with the constructor registered for their
+//     identifier (see RegisterLDtkEntity).
+//
+// Layer IDs are "level/layer", using the identifiers. Flipped tiles are not
+// supported, and where tiles are stacked in one cell only the top tile is
+// kept.
+func LoadLDtk(assets fs.FS, path string, solidValues ...int) ([]*Scene, error) {
+	p, err := loadLDtkProject(assets, path)
+	if err != nil {
+		return nil, err
+	}
+	scenes := make([]*Scene, 0, len(p.Levels))
+	for _, lev := range p.Levels {
+		sc, err := p.buildLevel(lev, solidValues)
+		if err != nil {
+			return nil, fmt.Errorf("loading %s: %w", path, err)
+		}
+		scenes = append(scenes, sc)
+	}
+	return scenes, nil
+}
+
+// The types below are the subset of the LDtk JSON format that is used.
+
+type ldtkProject struct {
+	Defs struct {
+		Tilesets []*ldtkTileset `json:"tilesets"`
+	} `json:"defs"`
+	Levels []*ldtkLevel `json:"levels"`
+
+	dir string // directory containing the project
+}
+
+type ldtkTileset struct {
+	UID          int    `json:"uid"`
+	Identifier   string `json:"identifier"`
+	RelPath      string `json:"relPath"`
+	TileGridSize int    `json:"tileGridSize"`
+	Spacing      int    `json:"spacing"`
+	Padding      int    `json:"padding"`
+}
+
+type ldtkLevel struct {
+	Identifier      string           `json:"identifier"`
+	PxWid           int              `json:"pxWid"`
+	PxHei           int              `json:"pxHei"`
+	ExternalRelPath string           `json:"externalRelPath"`
+	LayerInstances  []*ldtkLayerInst `json:"layerInstances"`
+}
+
+type ldtkLayerInst struct {
+	Identifier      string           `json:"__identifier"`
+	Type            string           `json:"__type"`
+	CWid            int              `json:"__cWid"`
+	CHei            int              `json:"__cHei"`
+	GridSize        int              `json:"__gridSize"`
+	TilesetDefUID   *int             `json:"__tilesetDefUid"`
+	PxTotalOffsetX  int              `json:"__pxTotalOffsetX"`
+	PxTotalOffsetY  int              `json:"__pxTotalOffsetY"`
+	Visible         bool             `json:"visible"`
+	IntGridCSV      []int            `json:"intGridCsv"`
+	AutoLayerTiles  []ldtkTile       `json:"autoLayerTiles"`
+	GridTiles       []ldtkTile       `json:"gridTiles"`
+	EntityInstances []ldtkEntityInst `json:"entityInstances"`
+}
+
+type ldtkTile struct {
+	Px [2]int `json:"px"`
+	T  int    `json:"t"`
+}
+
+type ldtkEntityInst struct {
+	Identifier     string          `json:"__identifier"`
+	Grid           [2]int          `json:"__grid"`
+	IID            string          `json:"iid"`
+	Width          int             `json:"width"`
+	Height         int             `json:"height"`
+	Px             [2]int          `json:"px"`
+	FieldInstances []ldtkFieldInst `json:"fieldInstances"`
+}
+
+type ldtkFieldInst struct {
+	Identifier string          `json:"__identifier"`
+	Type       string          `json:"__type"`
+	Value      json.RawMessage `json:"__value"`
+}
+
+func loadLDtkProject(assets fs.FS, name string) (*ldtkProject, error) {
+	p := &ldtkProject{dir: path.Dir(name)}
+	if err := decodeLDtk(p, assets, name); err != nil {
+		return nil, err
+	}
+	// Levels may be saved in separate files.
+	for _, lev := range p.Levels {
+		if lev.LayerInstances != nil || lev.ExternalRelPath == "" {
+			continue
+		}
+		if err := decodeLDtk(lev, assets, path.Join(p.dir, lev.ExternalRelPath)); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func decodeLDtk(dst any, assets fs.FS, name string) error {
+	f, err := assets.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(dst); err != nil {
+		return fmt.Errorf("decoding %s: %w", name, err)
+	}
+	return nil
+}
+
+func (p *ldtkProject) tileset(uid int) *ldtkTileset {
+	for _, ts := range p.Defs.Tilesets {
+		if ts.UID == uid {
+			return ts
+		}
+	}
+	return nil
+}
+
+func (p *ldtkProject) buildLevel(lev *ldtkLevel, solidValues []int) (*Scene, error) {
+	var solid map[int]bool
+	if len(solidValues) > 0 {
+		solid = make(map[int]bool, len(solidValues))
+		for _, v := range solidValues {
+			solid[v] = true
+		}
+	}
+	items := MakeContainer()
+	// LDtk lists layers from top to bottom.
+	for i := len(lev.LayerInstances) - 1; i >= 0; i-- {
+		li := lev.LayerInstances[i]
+		if err := p.addLayer(items, lev, li, solid); err != nil {
+			return nil, fmt.Errorf("level %q layer %q: %w", lev.Identifier, li.Identifier, err)
+		}
+	}
+	return &Scene{
+		ID:     ID(lev.Identifier),
+		Bounds: Bounds(image.Rect(0, 0, lev.PxWid, lev.PxHei)),
+		Child:  items,
+	}, nil
+}
+
+func (p *ldtkProject) addLayer(items *Container, lev *ldtkLevel, li *ldtkLayerInst, solid map[int]bool) error {
+	id := ID(lev.Identifier + "/" + li.Identifier)
+	offset := image.Pt(li.PxTotalOffsetX, li.PxTotalOffsetY)
+	switch li.Type {
+	case "IntGrid":
+		if len(li.IntGridCSV) != li.CWid*li.CHei {
+			return fmt.Errorf("got %d values, want %d×%d", len(li.IntGridCSV), li.CWid, li.CHei)
+		}
+		items.Add(&IntGrid{
+			ID:       id + "/collision",
+			Offset:   offset,
+			CellSize: image.Pt(li.GridSize, li.GridSize),
+			Size:     image.Pt(li.CWid, li.CHei),
+			Values:   li.IntGridCSV,
+			Solid:    solid,
+		})
+		if len(li.AutoLayerTiles) == 0 {
+			return nil
+		}
+		fallthrough
+
+	case "AutoLayer", "Tiles":
+		tiles := append(append([]ldtkTile(nil), li.AutoLayerTiles...), li.GridTiles...)
+		if len(tiles) == 0 {
+			return nil
+		}
+		if li.TilesetDefUID == nil {
+			return errors.New("layer has tiles but no tileset")
+		}
+		ts := p.tileset(*li.TilesetDefUID)
+		if ts == nil {
+			return fmt.Errorf("missing tileset %d", *li.TilesetDefUID)
+		}
+		if ts.Spacing != 0 || ts.Padding != 0 {
+			return fmt.Errorf("tileset %q: tilesets with spacing or padding are not supported", ts.Identifier)
+		}
+		if ts.TileGridSize != li.GridSize {
+			return fmt.Errorf("tileset %q grid size %d differs from layer grid size %d", ts.Identifier, ts.TileGridSize, li.GridSize)
+		}
+		cellSize := image.Pt(li.GridSize, li.GridSize)
+		m := make(map[image.Point]Tile, len(tiles))
+		for _, t := range tiles {
+			// Later tiles are drawn over earlier tiles.
+			m[geom.CDiv(image.Pt(t.Px[0], t.Px[1]), cellSize)] = StaticTile(t.T)
+		}
+		items.Add(&Tilemap{
+			ID:     id,
+			Hides:  Hides(!li.Visible),
+			Map:    m,
+			Ersatz: true, // IntGrids handle collisions
+			Offset: offset,
+			Sheet: Sheet{
+				CellSize: cellSize,
+				Src:      ImageRef{Path: path.Join(p.dir, ts.RelPath)},
+			},
+		})
+
+	case "Entities":
+		for _, ei := range li.EntityInstances {
+			c, err := ei.build(offset)
+			if err != nil {
+				return fmt.Errorf("entity %q: %w", ei.IID, err)
+			}
+			if c != nil {
+				items.Add(c)
+			}
+		}
+
+	default:
+		log.Printf("LDtk: skipping layer %q of unknown type %q", li.Identifier, li.Type)
+	}
+	return nil
+}
+
+// build calls the registered constructor for the entity, if any.
+func (ei *ldtkEntityInst) build(offset image.Point) (any, error) {
+	ctor := ldtkEntities[ei.Identifier]
+	if ctor == nil {
+		log.Printf("LDtk: skipping entity %q with unregistered identifier %q", ei.IID, ei.Identifier)
+		return nil, nil
+	}
+	e := LDtkEntity{
+		Identifier: ei.Identifier,
+		IID:        ei.IID,
+		Pos:        offset.Add(image.Pt(ei.Px[0], ei.Px[1])),
+		Size:       image.Pt(ei.Width, ei.Height),
+		Grid:       image.Pt(ei.Grid[0], ei.Grid[1]),
+		Fields:     make(map[string]any, len(ei.FieldInstances)),
+	}
+	for _, f := range ei.FieldInstances {
+		var v any
+		switch f.Type {
+		case "Int":
+			var n *int
+			if err := json.Unmarshal(f.Value, &n); err != nil {
+				return nil, fmt.Errorf("field %q: %w", f.Identifier, err)
+			}
+			if n != nil {
+				v = *n
+			}
+		default:
+			if err := json.Unmarshal(f.Value, &v); err != nil {
+				return nil, fmt.Errorf("field %q: %w", f.Identifier, err)
+			}
+		}
+		e.Fields[f.Identifier] = v
+	}
+	return ctor(e)
+}
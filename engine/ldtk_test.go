@@ -0,0 +1,205 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"image"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DrJosh9000/ichigo/geom"
+	"github.com/google/go-cmp/cmp"
+)
+
+const ldtkTestProject = `{
+	"defs": {
+		"tilesets": [
+			{"uid": 7, "identifier": "Tiles", "relPath": "../images/tiles.png", "tileGridSize": 8, "spacing": 0, "padding": 0}
+		]
+	},
+	"levels": [
+		{
+			"identifier": "Level_0",
+			"pxWid": 24,
+			"pxHei": 16,
+			"layerInstances": [
+				{
+					"__identifier": "Entities",
+					"__type": "Entities",
+					"__cWid": 3, "__cHei": 2, "__gridSize": 8,
+					"__tilesetDefUid": null,
+					"__pxTotalOffsetX": 0, "__pxTotalOffsetY": 0,
+					"visible": true,
+					"entityInstances": [
+						{
+							"__identifier": "Door",
+							"__grid": [1, 1],
+							"iid": "door-1",
+							"width": 8, "height": 8,
+							"px": [12, 16],
+							"fieldInstances": [
+								{"__identifier": "target", "__type": "String", "__value": "Level_1"},
+								{"__identifier": "depth", "__type": "Int", "__value": 3}
+							]
+						},
+						{
+							"__identifier": "Mystery",
+							"__grid": [0, 0],
+							"iid": "mystery-1",
+							"width": 8, "height": 8,
+							"px": [0, 0],
+							"fieldInstances": []
+						}
+					]
+				},
+				{
+					"__identifier": "Decor",
+					"__type": "Tiles",
+					"__cWid": 3, "__cHei": 2, "__gridSize": 8,
+					"__tilesetDefUid": 7,
+					"__pxTotalOffsetX": 0, "__pxTotalOffsetY": 0,
+					"visible": false,
+					"gridTiles": [{"px": [16, 0], "src": [40, 0], "f": 0, "t": 5}]
+				},
+				{
+					"__identifier": "Collisions",
+					"__type": "IntGrid",
+					"__cWid": 3, "__cHei": 2, "__gridSize": 8,
+					"__tilesetDefUid": 7,
+					"__pxTotalOffsetX": 0, "__pxTotalOffsetY": 4,
+					"visible": true,
+					"intGridCsv": [0, 0, 0, 1, 2, 1],
+					"autoLayerTiles": [
+						{"px": [0, 8], "src": [8, 0], "f": 0, "t": 1},
+						{"px": [8, 8], "src": [16, 0], "f": 0, "t": 2},
+						{"px": [8, 8], "src": [24, 0], "f": 0, "t": 3}
+					]
+				}
+			]
+		}
+	]
+}`
+
+type ldtkTestDoor struct {
+	ID
+	Pos    image.Point
+	Target string
+	Depth  int
+}
+
+func TestLoadLDtk(t *testing.T) {
+	RegisterLDtkEntity("Door", func(e LDtkEntity) (any, error) {
+		return &ldtkTestDoor{
+			ID:     ID(e.IID),
+			Pos:    e.Pos,
+			Target: e.Fields["target"].(string),
+			Depth:  e.Fields["depth"].(int),
+		}, nil
+	})
+	t.Cleanup(func() { delete(ldtkEntities, "Door") })
+
+	assets := fstest.MapFS{
+		"levels/project.ldtk": {Data: []byte(ldtkTestProject)},
+	}
+	scenes, err := LoadLDtk(assets, "levels/project.ldtk", 1)
+	if err != nil {
+		t.Fatalf("LoadLDtk = %v", err)
+	}
+	if len(scenes) != 1 {
+		t.Fatalf("len(scenes) = %d, want 1", len(scenes))
+	}
+	sc := scenes[0]
+	if got, want := sc.Ident(), "Level_0"; got != want {
+		t.Errorf("Ident() = %q, want %q", got, want)
+	}
+	if got, want := sc.BoundingRect(), image.Rect(0, 0, 24, 16); got != want {
+		t.Errorf("BoundingRect() = %v, want %v", got, want)
+	}
+
+	grid := &IntGrid{
+		ID:       "Level_0/Collisions/collision",
+		Offset:   image.Pt(0, 4),
+		CellSize: image.Pt(8, 8),
+		Size:     image.Pt(3, 2),
+		Values:   []int{0, 0, 0, 1, 2, 1},
+		Solid:    map[int]bool{1: true},
+	}
+	sheet := Sheet{
+		CellSize: image.Pt(8, 8),
+		Src:      ImageRef{Path: "images/tiles.png"},
+	}
+	want := []any{
+		grid,
+		&Tilemap{
+			ID: "Level_0/Collisions",
+			Map: map[image.Point]Tile{
+				{0, 1}: StaticTile(1),
+				{1, 1}: StaticTile(3),
+			},
+			Ersatz: true,
+			Offset: image.Pt(0, 4),
+			Sheet:  sheet,
+		},
+		&Tilemap{
+			ID:     "Level_0/Decor",
+			Hides:  true,
+			Map:    map[image.Point]Tile{{2, 0}: StaticTile(5)},
+			Ersatz: true,
+			Sheet:  sheet,
+		},
+		&ldtkTestDoor{
+			ID:     "door-1",
+			Pos:    image.Pt(12, 16),
+			Target: "Level_1",
+			Depth:  3,
+		},
+	}
+	if diff := cmp.Diff(sc.Child.(*Container).items, want, cmp.AllowUnexported(ImageRef{}, Sheet{}, Tilemap{}, chunkCache{})); diff != "" {
+		t.Errorf("items diff (-got +want):\n%s", diff)
+	}
+
+	// Value 2 is not solid, and the grid is offset by 4.
+	tests := []struct {
+		box  geom.Box
+		want bool
+	}{
+		{geom.Box{Min: geom.Pt3(0, 12, 0), Max: geom.Pt3(8, 20, 1)}, true},
+		{geom.Box{Min: geom.Pt3(8, 12, 0), Max: geom.Pt3(16, 20, 1)}, false},
+		{geom.Box{Min: geom.Pt3(0, 4, 0), Max: geom.Pt3(24, 12, 1)}, false},
+		{geom.Box{Min: geom.Pt3(20, 0, 0), Max: geom.Pt3(30, 13, 1)}, true},
+		// Just left of the grid, next to a solid cell.
+		{geom.Box{Min: geom.Pt3(-8, 12, 0), Max: geom.Pt3(0, 20, 1)}, false},
+		{geom.Box{Min: geom.Pt3(-8, 12, 0), Max: geom.Pt3(1, 20, 1)}, true},
+	}
+	for _, test := range tests {
+		if got := grid.CollidesWith(test.box); got != test.want {
+			t.Errorf("grid.CollidesWith(%v) = %v, want %v", test.box, got, test.want)
+		}
+	}
+	for _, test := range []struct {
+		wc   image.Point
+		want int
+	}{
+		{image.Pt(0, 12), 1},
+		{image.Pt(-1, 12), 0},
+		{image.Pt(0, 3), 0},
+	} {
+		if got := grid.ValueAt(test.wc); got != test.want {
+			t.Errorf("grid.ValueAt(%v) = %d, want %d", test.wc, got, test.want)
+		}
+	}
+}
@@ -24,7 +24,8 @@ import (
 
 // LoadingSwitch switches between two subcomponents. While After is being
 // loaded asynchronously, During is shown. Once loading is complete, During
-// is hidden and After is shown.
+// is hidden and After is shown. For switching between more than two scenes, or
+// with transitions, see SceneManager.
 type LoadingSwitch struct {
 	During, After interface {
 		Disabler
@@ -0,0 +1,557 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"encoding/gob"
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+var (
+	_ interface {
+		Disabler
+		Hider
+		Identifier
+		Layouter
+		Prepper
+		Scanner
+		Updater
+	} = &Menu{}
+
+	_ interface {
+		Widget
+		Prepper
+	} = &Button{}
+	_ interface {
+		Widget
+		Prepper
+	} = &Toggle{}
+	_ interface {
+		Widget
+		Prepper
+	} = &Slider{}
+	_ interface {
+		Widget
+		Prepper
+	} = &List{}
+)
+
+func init() {
+	gob.Register(&Menu{})
+	gob.Register(&Button{})
+	gob.Register(&Toggle{})
+	gob.Register(&Slider{})
+	gob.Register(&List{})
+}
+
+// MenuAction is an input to a menu.
+type MenuAction int
+
+// Menu actions.
+const (
+	MenuNone MenuAction = iota
+	MenuUp
+	MenuDown
+	MenuLeft
+	MenuRight
+	MenuConfirm
+	MenuCancel
+)
+
+// Keyboard keys and standard gamepad buttons for each action.
+var (
+	menuKeys = map[MenuAction][]ebiten.Key{
+		MenuUp:      {ebiten.KeyUp, ebiten.KeyW},
+		MenuDown:    {ebiten.KeyDown, ebiten.KeyS},
+		MenuLeft:    {ebiten.KeyLeft, ebiten.KeyA},
+		MenuRight:   {ebiten.KeyRight, ebiten.KeyD},
+		MenuConfirm: {ebiten.KeyEnter, ebiten.KeySpace, ebiten.KeyZ},
+		MenuCancel:  {ebiten.KeyEscape, ebiten.KeyBackspace, ebiten.KeyX},
+	}
+	menuButtons = map[MenuAction]ebiten.StandardGamepadButton{
+		MenuUp:      ebiten.StandardGamepadButtonLeftTop,
+		MenuDown:    ebiten.StandardGamepadButtonLeftBottom,
+		MenuLeft:    ebiten.StandardGamepadButtonLeftLeft,
+		MenuRight:   ebiten.StandardGamepadButtonLeftRight,
+		MenuConfirm: ebiten.StandardGamepadButtonRightBottom,
+		MenuCancel:  ebiten.StandardGamepadButtonRightRight,
+	}
+)
+
+// MenuEvent describes something that happened in a menu: a widget was
+// activated or changed, or the menu was cancelled.
+type MenuEvent struct {
+	Menu   *Menu
+	Widget Widget // nil if the menu was cancelled
+	Action string // the widget's Action
+	Value  any    // Toggle: bool, Slider: int, List: int (index)
+	Cancel bool   // the menu was cancelled
+}
+
+// MenuHandler components receive events from menus.
+type MenuHandler interface {
+	HandleMenuEvent(MenuEvent) error
+}
+
+// Widget components are focusable elements of a menu.
+type Widget interface {
+	BoundingRecter
+	Layouter
+
+	// SetFocus tells the widget if it has the focus.
+	SetFocus(focused bool, colour color.Color)
+
+	// Act handles an action while the widget has the focus. It returns
+	// whether the action was handled (if not, the menu may use it to move
+	// the focus, etc), and an event to send (or nil).
+	Act(MenuAction) (bool, *MenuEvent)
+}
+
+// Menu handles focus navigation and input for the widgets beneath it. Widgets
+// are found by scanning Child (but not within nested menus). The direction
+// actions move the focus to the nearest widget in that direction, unless the
+// focused widget uses them (e.g. a Slider uses left and right).
+//
+// Events are sent to the component with ID HandlerID (which must be a
+// MenuHandler), and to OnEvent (which is not saved).
+type Menu struct {
+	ID
+	Disables
+	Hides
+	Child       any
+	HandlerID   string
+	FocusColour color.Color // text colour of the focused widget; nil means yellow
+
+	OnEvent func(MenuEvent) error
+
+	handler MenuHandler
+	widgets []Widget
+	focus   int
+}
+
+// Focused returns the focused widget, or nil if there are no widgets.
+func (m *Menu) Focused() Widget {
+	if m.focus < 0 || m.focus >= len(m.widgets) {
+		return nil
+	}
+	return m.widgets[m.focus]
+}
+
+// Layout lays out the child.
+func (m *Menu) Layout(r image.Rectangle) {
+	layoutChild(m.Child, r)
+}
+
+// MinSize returns the child's MinSize.
+func (m *Menu) MinSize() image.Point {
+	return minSize(m.Child)
+}
+
+// Prepare finds the handler and the widgets.
+func (m *Menu) Prepare(game *Game) error {
+	if m.HandlerID != "" {
+		h, ok := game.Component(m.HandlerID).(MenuHandler)
+		if !ok {
+			return fmt.Errorf("component %q not MenuHandler", m.HandlerID)
+		}
+		m.handler = h
+	}
+	m.Refresh()
+	return nil
+}
+
+// Refresh finds all the widgets again (e.g. after adding or removing some),
+// and focuses the first one.
+func (m *Menu) Refresh() {
+	m.widgets = m.widgets[:0]
+	var walk func(any) error
+	walk = func(x any) error {
+		if w, ok := x.(Widget); ok {
+			m.widgets = append(m.widgets, w)
+		}
+		if _, nested := x.(*Menu); nested && x != m {
+			return nil
+		}
+		if sc, ok := x.(Scanner); ok {
+			return sc.Scan(walk)
+		}
+		return nil
+	}
+	walk(m.Child)
+	m.setFocus(0)
+}
+
+// Scan visits m.Child.
+func (m *Menu) Scan(visit VisitFunc) error {
+	return visit(m.Child)
+}
+
+func (m *Menu) String() string { return "Menu" }
+
+// Update reads input, and acts on it.
+func (m *Menu) Update() error {
+	return m.act(menuInput())
+}
+
+// act applies an action to the focused widget, or the menu.
+func (m *Menu) act(a MenuAction) error {
+	if a == MenuNone {
+		return nil
+	}
+	if w := m.Focused(); w != nil {
+		handled, ev := w.Act(a)
+		if ev != nil {
+			ev.Widget = w
+			if err := m.send(*ev); err != nil {
+				return err
+			}
+		}
+		if handled {
+			return nil
+		}
+	}
+	switch a {
+	case MenuUp, MenuDown, MenuLeft, MenuRight:
+		rects := make([]image.Rectangle, len(m.widgets))
+		for i, w := range m.widgets {
+			rects[i] = w.BoundingRect()
+		}
+		m.setFocus(nextFocus(rects, m.focus, a))
+	case MenuCancel:
+		return m.send(MenuEvent{Cancel: true})
+	}
+	return nil
+}
+
+// send sends an event to the handler and OnEvent.
+func (m *Menu) send(ev MenuEvent) error {
+	ev.Menu = m
+	if m.handler != nil {
+		if err := m.handler.HandleMenuEvent(ev); err != nil {
+			return err
+		}
+	}
+	if m.OnEvent != nil {
+		return m.OnEvent(ev)
+	}
+	return nil
+}
+
+// setFocus moves the focus to widget i.
+func (m *Menu) setFocus(i int) {
+	fc := m.FocusColour
+	if fc == nil {
+		fc = color.RGBA{R: 0xff, G: 0xff, A: 0xff}
+	}
+	if w := m.Focused(); w != nil {
+		w.SetFocus(false, nil)
+	}
+	m.focus = i
+	if w := m.Focused(); w != nil {
+		w.SetFocus(true, fc)
+	}
+}
+
+// menuInput returns the action for the keys or gamepad buttons that were just
+// pressed.
+func menuInput() MenuAction {
+	for a := MenuUp; a <= MenuCancel; a++ {
+		for _, k := range menuKeys[a] {
+			if inpututil.IsKeyJustPressed(k) {
+				return a
+			}
+		}
+	}
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		for a := MenuUp; a <= MenuCancel; a++ {
+			if inpututil.IsStandardGamepadButtonJustPressed(id, menuButtons[a]) {
+				return a
+			}
+		}
+	}
+	return MenuNone
+}
+
+// nextFocus returns the index of the nearest rectangle in a direction from
+// rects[from], or from if there is none. Rectangles that are further off to
+// the side are considered further away.
+func nextFocus(rects []image.Rectangle, from int, dir MenuAction) int {
+	if from < 0 || from >= len(rects) {
+		return from
+	}
+	centre := func(r image.Rectangle) image.Point {
+		return r.Min.Add(r.Max).Div(2)
+	}
+	c := centre(rects[from])
+	best, bestScore := from, 0
+	for i, r := range rects {
+		if i == from {
+			continue
+		}
+		d := centre(r).Sub(c)
+		var along, across int
+		switch dir {
+		case MenuUp:
+			along, across = -d.Y, d.X
+		case MenuDown:
+			along, across = d.Y, d.X
+		case MenuLeft:
+			along, across = -d.X, d.Y
+		case MenuRight:
+			along, across = d.X, d.Y
+		}
+		if along <= 0 {
+			continue
+		}
+		if across < 0 {
+			across = -across
+		}
+		if score := along + 2*across; best == from || score < bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// WidgetBase implements the parts of Widget common to Button, Toggle, Slider,
+// and List: a label drawn with Text, and the rectangle from layout. Each widget
+// sets the text (from the label and value) in Prepare, Layout, and Act.
+type WidgetBase struct {
+	Label  string
+	Action string // included in events
+	Text   Text   // draws the label; Text.Text is set by the widget
+
+	rect image.Rectangle
+}
+
+// BoundingRect returns the rectangle from the last layout.
+func (w *WidgetBase) BoundingRect() image.Rectangle { return w.rect }
+
+// layout sets the text, stores the rectangle, and positions the text.
+func (w *WidgetBase) layout(r image.Rectangle, text string) {
+	w.Text.Text = text
+	w.rect = r
+	w.Text.Layout(r)
+}
+
+// Scan visits &w.Text.
+func (w *WidgetBase) Scan(visit VisitFunc) error {
+	return visit(&w.Text)
+}
+
+// SetFocus highlights the text.
+func (w *WidgetBase) SetFocus(focused bool, c color.Color) {
+	w.Text.highlight = c
+}
+
+// minSize returns the size of the text, if it were set to text.
+func (w *WidgetBase) minSize(text string) image.Point {
+	t := w.Text
+	t.Text = text
+	return t.MinSize()
+}
+
+// Button is a widget that sends an event when confirmed.
+type Button struct {
+	ID
+	Hides
+	WidgetBase
+}
+
+// Act sends an event on confirm.
+func (b *Button) Act(a MenuAction) (bool, *MenuEvent) {
+	if a != MenuConfirm {
+		return false, nil
+	}
+	return true, &MenuEvent{Action: b.Action}
+}
+
+// MinSize returns the size of the label.
+func (b *Button) MinSize() image.Point { return b.minSize(b.Label) }
+
+// Layout sets the text and lays it out within r.
+func (b *Button) Layout(r image.Rectangle) { b.layout(r, b.Label) }
+
+// Prepare sets the text.
+func (b *Button) Prepare(*Game) error {
+	b.Text.Text = b.Label
+	return nil
+}
+
+func (b *Button) String() string { return "Button(" + b.Label + ")" }
+
+// Toggle is a widget with an on/off value, changed by confirm, left, or right.
+type Toggle struct {
+	ID
+	Hides
+	WidgetBase
+	On      bool
+	OnText  string // shown after the label when on; empty means "On"
+	OffText string // shown after the label when off; empty means "Off"
+}
+
+// Act flips the value.
+func (t *Toggle) Act(a MenuAction) (bool, *MenuEvent) {
+	switch a {
+	case MenuConfirm, MenuLeft, MenuRight:
+		t.On = !t.On
+		t.Text.Text = t.text()
+		return true, &MenuEvent{Action: t.Action, Value: t.On}
+	}
+	return false, nil
+}
+
+// MinSize returns the size of the label and value.
+func (t *Toggle) MinSize() image.Point { return t.minSize(t.text()) }
+
+// Layout sets the text and lays it out within r.
+func (t *Toggle) Layout(r image.Rectangle) { t.layout(r, t.text()) }
+
+// Prepare sets the text.
+func (t *Toggle) Prepare(*Game) error {
+	t.Text.Text = t.text()
+	return nil
+}
+
+func (t *Toggle) String() string { return "Toggle(" + t.Label + ")" }
+
+func (t *Toggle) text() string {
+	if t.On {
+		return t.Label + ": " + orDefault(t.OnText, "On")
+	}
+	return t.Label + ": " + orDefault(t.OffText, "Off")
+}
+
+// Slider is a widget with an integer value, changed by left and right.
+type Slider struct {
+	ID
+	Hides
+	WidgetBase
+	Value    int
+	Min, Max int
+	Step     int // zero means 1
+}
+
+// Act changes the value.
+func (s *Slider) Act(a MenuAction) (bool, *MenuEvent) {
+	step := s.Step
+	if step == 0 {
+		step = 1
+	}
+	v := s.Value
+	switch a {
+	case MenuLeft:
+		v -= step
+	case MenuRight:
+		v += step
+	default:
+		return false, nil
+	}
+	if v < s.Min {
+		v = s.Min
+	}
+	if v > s.Max {
+		v = s.Max
+	}
+	if v == s.Value {
+		return true, nil
+	}
+	s.Value = v
+	s.Text.Text = s.text()
+	return true, &MenuEvent{Action: s.Action, Value: v}
+}
+
+// MinSize returns the size of the label and value.
+func (s *Slider) MinSize() image.Point { return s.minSize(s.text()) }
+
+// Layout sets the text and lays it out within r.
+func (s *Slider) Layout(r image.Rectangle) { s.layout(r, s.text()) }
+
+// Prepare sets the text.
+func (s *Slider) Prepare(*Game) error {
+	s.Text.Text = s.text()
+	return nil
+}
+
+func (s *Slider) String() string { return "Slider(" + s.Label + ")" }
+
+func (s *Slider) text() string { return fmt.Sprintf("%s: < %d >", s.Label, s.Value) }
+
+// List is a widget for choosing one of several options, changed by left and
+// right (and confirm, which moves to the next option).
+type List struct {
+	ID
+	Hides
+	WidgetBase
+	Options  []string
+	Selected int // index into Options
+}
+
+// Act changes the selected option.
+func (l *List) Act(a MenuAction) (bool, *MenuEvent) {
+	n := len(l.Options)
+	if n == 0 {
+		return false, nil
+	}
+	switch a {
+	case MenuLeft:
+		l.Selected = (l.Selected + n - 1) % n
+	case MenuRight, MenuConfirm:
+		l.Selected = (l.Selected + 1) % n
+	default:
+		return false, nil
+	}
+	l.Text.Text = l.text()
+	return true, &MenuEvent{Action: l.Action, Value: l.Selected}
+}
+
+// MinSize returns the size of the label and option.
+func (l *List) MinSize() image.Point { return l.minSize(l.text()) }
+
+// Layout sets the text and lays it out within r.
+func (l *List) Layout(r image.Rectangle) { l.layout(r, l.text()) }
+
+// Prepare sets the text.
+func (l *List) Prepare(*Game) error {
+	l.Text.Text = l.text()
+	return nil
+}
+
+func (l *List) String() string { return "List(" + l.Label + ")" }
+
+func (l *List) text() string {
+	if l.Selected < 0 || l.Selected >= len(l.Options) {
+		return l.Label + ": < >"
+	}
+	return l.Label + ": < " + l.Options[l.Selected] + " >"
+}
+
+// orDefault returns s, or def if s is empty.
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
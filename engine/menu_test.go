@@ -0,0 +1,138 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"image"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNextFocus(t *testing.T) {
+	// A 2x2 grid, plus one wide item below.
+	rects := []image.Rectangle{
+		image.Rect(0, 0, 10, 10),
+		image.Rect(20, 0, 30, 10),
+		image.Rect(0, 20, 10, 30),
+		image.Rect(20, 20, 30, 30),
+		image.Rect(0, 40, 30, 50),
+	}
+	tests := []struct {
+		from int
+		dir  MenuAction
+		want int
+	}{
+		{0, MenuRight, 1},
+		{0, MenuDown, 2},
+		{0, MenuUp, 0},
+		{0, MenuLeft, 0},
+		{3, MenuLeft, 2},
+		{3, MenuUp, 1},
+		{2, MenuDown, 4},
+		{4, MenuUp, 2},
+	}
+	for _, test := range tests {
+		if got := nextFocus(rects, test.from, test.dir); got != test.want {
+			t.Errorf("nextFocus(rects, %d, %d) = %d, want %d", test.from, test.dir, got, test.want)
+		}
+	}
+}
+
+func TestMenuEvents(t *testing.T) {
+	font := testFont()
+	start := &Button{WidgetBase: WidgetBase{Label: "Start", Action: "start"}}
+	sound := &Toggle{WidgetBase: WidgetBase{Label: "Sound", Action: "sound"}}
+	volume := &Slider{WidgetBase: WidgetBase{Label: "Volume", Action: "volume"}, Value: 5, Max: 10, Step: 5}
+	for _, w := range []*WidgetBase{&start.WidgetBase, &sound.WidgetBase, &volume.WidgetBase} {
+		w.Text.font = font
+	}
+	var got []MenuEvent
+	m := &Menu{
+		Child: &Stack{Items: []any{start, sound, volume}},
+		OnEvent: func(ev MenuEvent) error {
+			ev.Menu = nil
+			got = append(got, ev)
+			return nil
+		},
+	}
+	m.Layout(image.Rect(0, 0, 320, 240))
+	m.Refresh()
+	if m.Focused() != start {
+		t.Fatalf("initial focus = %v, want %v", m.Focused(), start)
+	}
+
+	for _, a := range []MenuAction{
+		MenuConfirm, // start
+		MenuDown,    // focus sound
+		MenuConfirm, // sound on
+		MenuDown,    // focus volume
+		MenuRight,   // volume 10
+		MenuRight,   // volume stays 10, no event
+		MenuUp,      // focus sound
+		MenuCancel,
+	} {
+		if err := m.act(a); err != nil {
+			t.Fatalf("m.act(%d) = %v, want nil", a, err)
+		}
+	}
+	want := []MenuEvent{
+		{Widget: start, Action: "start"},
+		{Widget: sound, Action: "sound", Value: true},
+		{Widget: volume, Action: "volume", Value: 10},
+		{Cancel: true},
+	}
+	if diff := cmp.Diff(got, want, cmp.Comparer(func(a, b Widget) bool { return a == b })); diff != "" {
+		t.Errorf("events diff (-got +want):\n%s", diff)
+	}
+	if m.Focused() != sound {
+		t.Errorf("final focus = %v, want %v", m.Focused(), sound)
+	}
+}
+
+func TestWidgetText(t *testing.T) {
+	font := testFont()
+	b := &Button{WidgetBase: WidgetBase{Label: "Start"}}
+	s := &Slider{WidgetBase: WidgetBase{Label: "Volume"}, Value: 5, Max: 10}
+	b.Text.font, s.Text.font = font, font
+
+	// MinSize doesn't change the text.
+	if got, want := b.MinSize(), image.Pt(40, 10); got != want {
+		t.Errorf("b.MinSize() = %v, want %v", got, want)
+	}
+	if b.Text.Text != "" {
+		t.Errorf("after MinSize: b.Text.Text = %q, want empty", b.Text.Text)
+	}
+
+	// Prepare sets the text.
+	if err := s.Prepare(nil); err != nil {
+		t.Fatalf("s.Prepare(nil) = %v, want nil", err)
+	}
+	if got, want := s.Text.Text, "Volume: < 5 >"; got != want {
+		t.Errorf("after Prepare: s.Text.Text = %q, want %q", got, want)
+	}
+
+	// A widget laid out directly (without MinSize) has its text.
+	m := &Menu{Child: b}
+	m.Layout(image.Rect(0, 0, 320, 240))
+	if got, want := b.Text.Text, "Start"; got != want {
+		t.Errorf("after Layout: b.Text.Text = %q, want %q", got, want)
+	}
+	if got, want := b.Text.Pos, image.Pt(0, 0); got != want {
+		t.Errorf("after Layout: b.Text.Pos = %v, want %v", got, want)
+	}
+}
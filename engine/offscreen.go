@@ -0,0 +1,117 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"encoding/gob"
+	"image"
+
+	"github.com/DrJosh9000/ichigo/geom"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+var _ interface {
+	Drawer
+	DrawManager
+	Hider
+	Identifier
+	Prepper
+	Scanner
+	Transformer
+} = &Offscreen{}
+
+func init() {
+	gob.Register(&Offscreen{})
+}
+
+// Offscreen is a DrawManager that draws its subtree into an image of a fixed
+// size, and then draws that image (using its own Transform). The subtree is
+// drawn into the image as though it were the whole screen, so, for example,
+// a low-resolution scene can be drawn at its natural size and then scaled up
+// with nearest-neighbour filtering, or a group of overlapping components can
+// be faded out as a whole.
+//
+// The subtree is drawn in the same way as DrawDFS. Note that DrawDAG does not
+// draw other DrawManagers, so Offscreen is not drawn if it is beneath one.
+type Offscreen struct {
+	ID
+	Child any
+	Hides
+
+	Size   image.Point // size of the image; zero means the screen size
+	Offset image.Point // where to draw the image
+	Scale  geom.Float2 // scales the image; zero means 1
+	Alpha  *float64    // opacity of the image; nil means 1 (fully opaque)
+
+	game  *Game
+	image *ebiten.Image
+}
+
+// Draw draws the subtree into the offscreen image, then draws the image.
+func (o *Offscreen) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
+	size := o.Size
+	if size == (image.Point{}) {
+		size = screen.Bounds().Size()
+	}
+	if o.image != nil {
+		if w, h := o.image.Size(); w != size.X || h != size.Y {
+			o.image.Dispose()
+			o.image = nil
+		}
+	}
+	if o.image == nil {
+		o.image = ebiten.NewImage(size.X, size.Y)
+	}
+	o.image.Clear()
+	drawDFS(o.game, o, o, o.image, &ebiten.DrawImageOptions{})
+	screen.DrawImage(o.image, opts)
+}
+
+// ManagesDrawingSubcomponents is present so Offscreen is recognised as a
+// DrawManager.
+func (*Offscreen) ManagesDrawingSubcomponents() {}
+
+// Prepare saves a reference to g.
+func (o *Offscreen) Prepare(g *Game) error {
+	o.game = g
+	return nil
+}
+
+// Scan visits o.Child.
+func (o *Offscreen) Scan(visit VisitFunc) error {
+	return visit(o.Child)
+}
+
+func (o *Offscreen) String() string { return "Offscreen" }
+
+// Transform returns the scale, alpha, and translation by Offset, for drawing
+// the offscreen image.
+func (o *Offscreen) Transform() (opts ebiten.DrawImageOptions) {
+	sx, sy := o.Scale.X, o.Scale.Y
+	if sx == 0 {
+		sx = 1
+	}
+	if sy == 0 {
+		sy = 1
+	}
+	opts.GeoM.Scale(sx, sy)
+	opts.GeoM.Translate(geom.CFloat(o.Offset))
+	if o.Alpha != nil {
+		opts.ColorM.Scale(1, 1, 1, *o.Alpha)
+	}
+	return opts
+}
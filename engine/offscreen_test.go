@@ -0,0 +1,126 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"image"
+	"testing"
+
+	"github.com/DrJosh9000/ichigo/geom"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestOffscreenTransform(t *testing.T) {
+	half, zero := 0.5, 0.0
+	tests := []struct {
+		name      string
+		o         *Offscreen
+		in, want  geom.Float2
+		wantAlpha float64
+	}{
+		{
+			name:      "defaults",
+			o:         &Offscreen{},
+			in:        geom.Float2{X: 3, Y: 4},
+			want:      geom.Float2{X: 3, Y: 4},
+			wantAlpha: 1,
+		},
+		{
+			name:      "scale X only",
+			o:         &Offscreen{Scale: geom.Float2{X: 2}},
+			in:        geom.Float2{X: 3, Y: 4},
+			want:      geom.Float2{X: 6, Y: 4},
+			wantAlpha: 1,
+		},
+		{
+			name:      "scale then offset",
+			o:         &Offscreen{Scale: geom.Float2{X: 2, Y: 3}, Offset: image.Pt(10, 20)},
+			in:        geom.Float2{X: 3, Y: 4},
+			want:      geom.Float2{X: 16, Y: 32},
+			wantAlpha: 1,
+		},
+		{
+			name:      "half alpha",
+			o:         &Offscreen{Alpha: &half},
+			wantAlpha: 0.5,
+		},
+		{
+			name:      "zero alpha is transparent",
+			o:         &Offscreen{Alpha: &zero},
+			wantAlpha: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := test.o.Transform()
+			x, y := opts.GeoM.Apply(test.in.X, test.in.Y)
+			if got := (geom.Float2{X: x, Y: y}); got != test.want {
+				t.Errorf("o.Transform().GeoM.Apply(%v) = %v, want %v", test.in, got, test.want)
+			}
+			if got := opts.ColorM.Element(3, 3); got != test.wantAlpha {
+				t.Errorf("o.Transform().ColorM.Element(3, 3) = %v, want %v", got, test.wantAlpha)
+			}
+		})
+	}
+}
+
+type fakeTransformer struct {
+	Child any
+	dx    float64
+}
+
+func (f *fakeTransformer) Scan(visit VisitFunc) error { return visit(f.Child) }
+
+func (f *fakeTransformer) Transform() (opts ebiten.DrawImageOptions) {
+	opts.GeoM.Translate(f.dx, 0)
+	return opts
+}
+
+func TestWalkDrawersManagerTransform(t *testing.T) {
+	// The manager (o) is a Transformer, but its transform is for drawing the
+	// offscreen image, so must not apply to the subtree. Popping after
+	// visiting the transformer child must not affect later siblings.
+	a, b, c := fakeDrawBoxer("a"), fakeDrawBoxer("b"), fakeDrawBoxer("c")
+	o := &Offscreen{
+		Scale:  geom.Float2{X: 2, Y: 2},
+		Offset: image.Pt(100, 0),
+		Child:  MakeContainer(a, &fakeTransformer{Child: b, dx: 10}, c),
+	}
+	g := &Game{Root: o}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v, want nil", err)
+	}
+
+	got := make(map[Drawer]geom.Float2)
+	walkDrawers(g, o, o, &ebiten.DrawImageOptions{}, func(d Drawer, st *drawState) {
+		x, y := st.opts.GeoM.Apply(1, 1)
+		got[d] = geom.Float2{X: x, Y: y}
+	})
+	want := map[Drawer]geom.Float2{
+		a: {X: 1, Y: 1},
+		b: {X: 11, Y: 1},
+		c: {X: 1, Y: 1},
+	}
+	for d, w := range want {
+		if p, ok := got[d]; !ok || p != w {
+			t.Errorf("walkDrawers: %v drawn at (1, 1) -> %v (visited = %t), want %v", d, p, ok, w)
+		}
+	}
+	if _, ok := got[o]; ok {
+		t.Error("walkDrawers visited the manager, want not visited")
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+var _ WritableFS = &OverlayFS{}
+
+// ErrReadOnlyFS is returned (wrapped) when trying to save into an asset FS
+// that can't be written to.
+var ErrReadOnlyFS = errors.New("asset FS is read-only")
+
+// WritableFS is an asset FS that can also write files. Savers should use it
+// (e.g. via SaveGobzFS) to write assets back to where they were loaded from.
+type WritableFS interface {
+	fs.FS
+
+	// WriteFile replaces the contents of the named file (which uses the same
+	// slash-separated path format as Open).
+	WriteFile(name string, data []byte) error
+}
+
+// OverlayFS is a WritableFS that reads files from a directory on disk,
+// falling back to a base FS (e.g. an embed.FS) when a file doesn't exist
+// there. Files are written into the directory. This is useful during
+// development: point Dir at the directory the embedded assets come from,
+// and edited assets are both visible immediately and saved to the source
+// tree. Directory listings are not merged.
+type OverlayFS struct {
+	Base fs.FS  // optional
+	Dir  string // directory on disk; if empty, the FS is read-only
+}
+
+// Open opens the named file from Dir if it exists there, or Base otherwise.
+func (o *OverlayFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if o.Dir != "" {
+		f, err := os.Open(filepath.Join(o.Dir, filepath.FromSlash(name)))
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	if o.Base == nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return o.Base.Open(name)
+}
+
+// WriteFile writes the named file into Dir, creating parent directories as
+// needed. The file is replaced atomically.
+func (o *OverlayFS) WriteFile(name string, data []byte) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+	if o.Dir == "" {
+		return &fs.PathError{Op: "write", Path: name, Err: ErrReadOnlyFS}
+	}
+	dst := filepath.Join(o.Dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(dst, data)
+}
+
+// SaveGobzFS gob-encodes and gzips src, and writes it to path within assets,
+// which must be a WritableFS.
+func SaveGobzFS(src any, assets fs.FS, path string) error {
+	w, ok := assets.(WritableFS)
+	if !ok {
+		return fmt.Errorf("saving %s: %w", path, ErrReadOnlyFS)
+	}
+	var buf bytes.Buffer
+	if err := writeGobz(&buf, src); err != nil {
+		return fmt.Errorf("saving %s: %w", path, err)
+	}
+	return w.WriteFile(path, buf.Bytes())
+}
+
+// writeFileAtomic writes data to a temporary file alongside name, and then
+// renames it over name, so that readers never see a partially-written file.
+func writeFileAtomic(name string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
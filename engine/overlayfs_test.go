@@ -0,0 +1,81 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"errors"
+	"image"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestOverlayFS(t *testing.T) {
+	dir := t.TempDir()
+	o := &OverlayFS{
+		Base: fstest.MapFS{
+			"assets/a.txt": {Data: []byte("base a")},
+			"assets/b.txt": {Data: []byte("base b")},
+		},
+		Dir: dir,
+	}
+	if err := o.WriteFile("assets/b.txt", []byte("disk b")); err != nil {
+		t.Fatalf("WriteFile(assets/b.txt) = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "assets", "b.txt")); err != nil {
+		t.Errorf("Stat(written file) = %v", err)
+	}
+
+	tests := map[string]string{
+		"assets/a.txt": "base a",
+		"assets/b.txt": "disk b",
+	}
+	for name, want := range tests {
+		got, err := fs.ReadFile(o, name)
+		if err != nil {
+			t.Errorf("ReadFile(%q) = %v", name, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("ReadFile(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if _, err := o.Open("assets/c.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Open(assets/c.txt) = %v, want %v", err, fs.ErrNotExist)
+	}
+}
+
+func TestSaveGobzFS(t *testing.T) {
+	o := &OverlayFS{Dir: t.TempDir()}
+	want := &Scene{ID: "scene", Bounds: Bounds(image.Rect(1, 2, 3, 4))}
+	if err := SaveGobzFS(want, o, "assets/scene.gobz"); err != nil {
+		t.Fatalf("SaveGobzFS = %v", err)
+	}
+	got := new(Scene)
+	if err := LoadGobz(got, o, "assets/scene.gobz"); err != nil {
+		t.Fatalf("LoadGobz = %v", err)
+	}
+	if got.ID != want.ID || got.Bounds != want.Bounds {
+		t.Errorf("LoadGobz got %v %v, want %v %v", got.ID, got.Bounds, want.ID, want.Bounds)
+	}
+
+	if err := SaveGobzFS(want, fstest.MapFS{}, "assets/scene.gobz"); !errors.Is(err, ErrReadOnlyFS) {
+		t.Errorf("SaveGobzFS(read-only) = %v, want %v", err, ErrReadOnlyFS)
+	}
+}
@@ -19,6 +19,7 @@ package engine
 import (
 	"encoding/gob"
 	"fmt"
+	"math"
 
 	"github.com/DrJosh9000/ichigo/geom"
 	"github.com/hajimehoshi/ebiten/v2"
@@ -28,6 +29,7 @@ var _ interface {
 	Prepper
 	Scanner
 	Transformer
+	Updater
 } = &Parallax{}
 
 func init() {
@@ -36,21 +38,62 @@ func init() {
 
 // Parallax is a container that translates based on the position of a
 // camera, intended to produce a "parallax" like effect.
+//
+// A factor of 0 means the child moves with the world (as if it were at the
+// same depth), and a factor of 1 means the child stays still relative to the
+// camera (as if it were infinitely far away).
+//
+// To tile the child to cover the screen, wrap it in a Repeat (Parallax does
+// not draw anything itself, so works within DrawDAG, but Repeat does not).
 type Parallax struct {
 	CameraID string
-	Factor   float64 // how much to translate in response to the camera
+	Factor   float64     // how much to translate in response to the camera
+	Factors  geom.Float2 // per-axis factors; used instead of Factor if non-zero
 	Child    any
 
-	camera *Camera
+	// If FocalLength is non-zero, the factors are derived from the Z position
+	// of Child (which must be a BoundingBoxer, or a Repeat of one) instead: a
+	// layer at depth d behind Z = 0 gets factor d / (d + FocalLength).
+	FocalLength float64
+
+	// Velocity scrolls the child automatically (pixels per tick), e.g. for
+	// clouds.
+	Velocity geom.Float2
+
+	camera  *Camera
+	factors geom.Float2
+	scroll  geom.Float2
 }
 
-// Prepare obtains a reference to the camera.
+// Prepare obtains a reference to the camera, and works out the factors.
 func (p *Parallax) Prepare(game *Game) error {
 	c, ok := game.Component(p.CameraID).(*Camera)
 	if !ok {
 		return fmt.Errorf("component %q type != *Camera", p.CameraID)
 	}
 	p.camera = c
+
+	switch {
+	case p.FocalLength != 0:
+		child := p.Child
+		if r, ok := child.(*Repeat); ok {
+			child = r.Child
+		}
+		bb, ok := child.(BoundingBoxer)
+		if !ok {
+			return fmt.Errorf("child %v not BoundingBoxer; needed for FocalLength", p.Child)
+		}
+		d := -float64(bb.BoundingBox().Min.Z)
+		if d+p.FocalLength <= 0 {
+			return fmt.Errorf("child depth %v is in front of the camera (focal length %v)", d, p.FocalLength)
+		}
+		f := d / (d + p.FocalLength)
+		p.factors = geom.Float2{X: f, Y: f}
+	case p.Factors != (geom.Float2{}):
+		p.factors = p.Factors
+	default:
+		p.factors = geom.Float2{X: p.Factor, Y: p.Factor}
+	}
 	return nil
 }
 
@@ -61,9 +104,31 @@ func (p *Parallax) Scan(visit VisitFunc) error {
 
 func (p *Parallax) String() string { return "Parallax" }
 
-// Transform returns a GeoM translation of Factor * camera.Centre.
+// Transform returns a GeoM translation of the factors times camera.Centre,
+// plus the scrolling offset.
 func (p *Parallax) Transform() (opts ebiten.DrawImageOptions) {
 	x, y := geom.CFloat(p.camera.Centre)
-	opts.GeoM.Translate(x*p.Factor, y*p.Factor)
+	opts.GeoM.Translate(x*p.factors.X+p.scroll.X, y*p.factors.Y+p.scroll.Y)
 	return opts
 }
+
+// Update scrolls by Velocity.
+func (p *Parallax) Update() error {
+	if p.Velocity == (geom.Float2{}) {
+		return nil
+	}
+	p.scroll.X += p.Velocity.X
+	p.scroll.Y += p.Velocity.Y
+	// Keep the offset small when repeating, since it is equivalent.
+	if r, ok := p.Child.(*Repeat); ok {
+		if tr, ok := r.tileRect(); ok {
+			if r.X && tr.Dx() > 0 {
+				p.scroll.X = math.Mod(p.scroll.X, float64(tr.Dx()))
+			}
+			if r.Y && tr.Dy() > 0 {
+				p.scroll.Y = math.Mod(p.scroll.Y, float64(tr.Dy()))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"image"
+	"testing"
+
+	"github.com/DrJosh9000/ichigo/geom"
+	"github.com/google/go-cmp/cmp"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestParallaxFactors(t *testing.T) {
+	far := &Parallax{
+		CameraID:    "camera",
+		FocalLength: 100,
+		Child: &Actor{
+			Pos:    geom.Pt3(0, 0, -100),
+			Bounds: geom.Box{Max: geom.Pt3(10, 10, 1)},
+		},
+	}
+	axes := &Parallax{
+		CameraID: "camera",
+		Factors:  geom.Float2{X: 0.25, Y: 1},
+		Velocity: geom.Float2{X: 2},
+		Child:    MakeContainer(),
+	}
+	cam := &Camera{ID: "camera", Child: MakeContainer(far, axes)}
+	g := &Game{Root: &DrawDFS{Child: cam}}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v, want nil", err)
+	}
+	cam.Centre = image.Pt(40, 80)
+	if err := g.Update(); err != nil {
+		t.Fatalf("g.Update() = %v, want nil", err)
+	}
+
+	tests := []struct {
+		name string
+		p    *Parallax
+		want geom.Float2
+	}{
+		{"focal length", far, geom.Float2{X: 20, Y: 40}},
+		{"per-axis with velocity", axes, geom.Float2{X: 12, Y: 80}},
+	}
+	for _, test := range tests {
+		opts := test.p.Transform()
+		x, y := opts.GeoM.Apply(0, 0)
+		if got := (geom.Float2{X: x, Y: y}); got != test.want {
+			t.Errorf("%s: Transform translates by %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+// fakeRecorder records where (0, 0) is drawn.
+type fakeRecorder struct {
+	box   geom.Box
+	drawn []geom.Float2
+}
+
+func (f *fakeRecorder) BoundingBox() geom.Box { return f.box }
+
+func (f *fakeRecorder) Draw(_ *ebiten.Image, opts *ebiten.DrawImageOptions) {
+	x, y := opts.GeoM.Apply(0, 0)
+	f.drawn = append(f.drawn, geom.Float2{X: x, Y: y})
+}
+
+func TestParallaxInDrawDAG(t *testing.T) {
+	// Parallax is not a DrawManager, so the DrawDAG draws its child, with the
+	// Parallax transform applied (and isn't culled based on the child's
+	// untransformed position).
+	rec := &fakeRecorder{box: geom.Box{Max: geom.Pt3(10, 10, 10)}}
+	p := &Parallax{CameraID: "camera", Factor: 0.5, Child: rec}
+	d := &DrawDAG{ChunkSize: 16, Child: p}
+	cam := &Camera{ID: "camera", Child: d, Zoom: 1}
+	g := &Game{
+		Root:       &DrawDFS{Child: cam},
+		ScreenSize: image.Pt(320, 240),
+		Projection: geom.ElevationProjection{},
+	}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v, want nil", err)
+	}
+	if got, want := d.Stats().Vertices, 1; got != want {
+		t.Errorf("d.Stats().Vertices = %d, want %d", got, want)
+	}
+
+	screen := ebiten.NewImage(320, 240)
+	defer screen.Dispose()
+	tests := []struct {
+		centre image.Point
+		want   geom.Float2
+	}{
+		{image.Pt(0, 0), geom.Float2{X: 160, Y: 120}},
+		{image.Pt(40, 80), geom.Float2{X: 140, Y: 80}},
+		// The child is at x in [0, 10), but drawn at 100 (and the screen
+		// shows x in [40, 360)).
+		{image.Pt(200, 0), geom.Float2{X: 60, Y: 120}},
+	}
+	for _, test := range tests {
+		rec.drawn = nil
+		cam.Centre = test.centre
+		g.Root.Draw(screen, &ebiten.DrawImageOptions{})
+		if diff := cmp.Diff(rec.drawn, []geom.Float2{test.want}); diff != "" {
+			t.Errorf("camera at %v: drawn positions diff:\n%s", test.centre, diff)
+		}
+	}
+}
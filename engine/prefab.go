@@ -0,0 +1,243 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/DrJosh9000/ichigo/geom"
+)
+
+var _ interface {
+	Identifier
+	Loader
+	Prepper
+	Saver
+} = &Prefab{}
+
+func init() {
+	gob.Register(&Prefab{})
+}
+
+var idType = reflect.TypeOf(ID(""))
+
+// Prefab is a reusable component subtree that can be instantiated many times.
+// Each instance is a deep copy of Template (via gob, so runtime state in
+// unexported fields starts fresh), with overrides applied, and with a unique
+// suffix ("#1", "#2", ...) added to every non-empty ID field.
+//
+// The template itself is not part of the game tree, so it is never loaded,
+// registered, prepared, etc. Only instances are.
+type Prefab struct {
+	ID
+	Path     string // if set, Template is loaded from this gobz asset
+	Template any
+	PosField string // field set by SpawnAt, e.g. "Sprite.Actor.Pos"
+
+	assets fs.FS
+	game   *Game
+	tmpl   []byte // gob-encoded prefabTemplate
+
+	mu    sync.Mutex
+	count int
+}
+
+// prefabTemplate is how templates are gob-encoded, so that the concrete type
+// is recorded.
+type prefabTemplate struct {
+	Template any
+}
+
+// Load loads the template from Path (if set), and encodes it ready for
+// copying.
+func (p *Prefab) Load(assets fs.FS) error {
+	p.assets = assets
+	if p.Path != "" {
+		var pt prefabTemplate
+		if err := LoadGobz(&pt, assets, p.Path); err != nil {
+			return err
+		}
+		p.Template = pt.Template
+	}
+	if p.Template == nil {
+		return fmt.Errorf("prefab %q has no template", p.ID)
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(prefabTemplate{p.Template}); err != nil {
+		return fmt.Errorf("encoding prefab %q template: %w", p.ID, err)
+	}
+	p.tmpl = buf.Bytes()
+	return nil
+}
+
+// Prepare saves a reference to the game, for spawning.
+func (p *Prefab) Prepare(game *Game) error {
+	p.game = game
+	return nil
+}
+
+// Save saves the template to Path.
+func (p *Prefab) Save() error {
+	if p.Path == "" {
+		return fmt.Errorf("prefab %q has no path to save to", p.ID)
+	}
+	return SaveGobzFS(&prefabTemplate{p.Template}, p.assets, p.Path)
+}
+
+// New returns a new instance of the template. overrides maps field paths
+// (relative to the template, e.g. "Sprite.Actor.Pos") to values for those
+// fields. The instance must be loaded, registered, and prepared before use
+// (or use Spawn).
+func (p *Prefab) New(overrides map[string]any) (any, error) {
+	if p.tmpl == nil {
+		return nil, fmt.Errorf("prefab %q not loaded", p.ID)
+	}
+	var pt prefabTemplate
+	if err := gob.NewDecoder(bytes.NewReader(p.tmpl)).Decode(&pt); err != nil {
+		return nil, fmt.Errorf("copying prefab %q template: %w", p.ID, err)
+	}
+	p.mu.Lock()
+	p.count++
+	n := p.count
+	p.mu.Unlock()
+	suffixIDs(reflect.ValueOf(pt.Template), fmt.Sprintf("#%d", n))
+	for path, value := range overrides {
+		if err := setField(pt.Template, path, value); err != nil {
+			return nil, fmt.Errorf("overriding %s: %w", path, err)
+		}
+	}
+	return pt.Template, nil
+}
+
+// Spawn creates a new instance (see New), and loads, registers (as a child
+// of parent), and prepares it.
+func (p *Prefab) Spawn(parent any, overrides map[string]any) (any, error) {
+	if p.game == nil {
+		return nil, fmt.Errorf("prefab %q not prepared", p.ID)
+	}
+	c, err := p.New(overrides)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.game.Load(c, p.assets); err != nil {
+		return nil, err
+	}
+	if err := p.game.PathRegister(c, parent); err != nil {
+		return nil, err
+	}
+	if err := p.game.Prepare(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SpawnAt spawns a new instance with the field named by PosField set to pos.
+func (p *Prefab) SpawnAt(parent any, pos geom.Int3) (any, error) {
+	if p.PosField == "" {
+		return nil, fmt.Errorf("prefab %q has no PosField", p.ID)
+	}
+	return p.Spawn(parent, map[string]any{p.PosField: pos})
+}
+
+func (p *Prefab) String() string { return "Prefab" }
+
+// suffixIDs appends suffix to every non-empty exported ID field reachable
+// from v.
+func suffixIDs(v reflect.Value, suffix string) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		if c, ok := v.Interface().(*Container); ok {
+			for _, x := range c.items {
+				suffixIDs(reflect.ValueOf(x), suffix)
+			}
+			return
+		}
+		suffixIDs(v.Elem(), suffix)
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f, fv := t.Field(i), v.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if f.Type == idType {
+				if id := fv.String(); id != "" && fv.CanSet() {
+					fv.SetString(id + suffix)
+				}
+				continue
+			}
+			suffixIDs(fv, suffix)
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			suffixIDs(v.Index(i), suffix)
+		}
+
+	case reflect.Map:
+		for it := v.MapRange(); it.Next(); {
+			suffixIDs(it.Value(), suffix)
+		}
+	}
+}
+
+// setField sets the field at the dot-separated path within root to value.
+func setField(root any, path string, value any) error {
+	v := reflect.ValueOf(root)
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return fmt.Errorf("nil value before field %s", name)
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf("%v is not a struct", v.Type())
+		}
+		t := v.Type()
+		v = v.FieldByName(name)
+		if !v.IsValid() {
+			return fmt.Errorf("%v has no field %s", t, name)
+		}
+	}
+	if !v.CanSet() {
+		return errors.New("field cannot be set")
+	}
+	x := reflect.ValueOf(value)
+	switch {
+	case !x.IsValid():
+		v.Set(reflect.Zero(v.Type()))
+	case x.Type().AssignableTo(v.Type()):
+		v.Set(x)
+	case x.Type().ConvertibleTo(v.Type()) && (x.Kind() == reflect.String) == (v.Kind() == reflect.String):
+		v.Set(x.Convert(v.Type()))
+	default:
+		return fmt.Errorf("cannot use %T as %v", value, v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"image"
+	"testing"
+
+	"github.com/DrJosh9000/ichigo/geom"
+)
+
+func TestPrefabSpawn(t *testing.T) {
+	prefab := &Prefab{
+		ID: "prefab",
+		Template: &Scene{
+			ID: "thing",
+			Child: MakeContainer(&SolidRect{
+				ID:  "part",
+				Box: geom.Box{Max: geom.Pt3(1, 1, 1)},
+			}),
+		},
+	}
+	parent := &Scene{ID: "parent", Child: MakeContainer()}
+	g := &Game{
+		Root: &DrawDFS{Child: MakeContainer(prefab, parent)},
+	}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v, want nil", err)
+	}
+
+	bounds := Bounds(image.Rect(1, 2, 3, 4))
+	for i, want := range []string{"thing#1", "thing#2"} {
+		c, err := prefab.Spawn(parent, map[string]any{"Bounds": bounds})
+		if err != nil {
+			t.Fatalf("prefab.Spawn(parent, overrides) = %v", err)
+		}
+		sc := c.(*Scene)
+		if got := sc.Ident(); got != want {
+			t.Errorf("spawn %d: Ident() = %q, want %q", i, got, want)
+		}
+		if sc.Bounds != bounds {
+			t.Errorf("spawn %d: Bounds = %v, want %v", i, sc.Bounds, bounds)
+		}
+		if got := g.Component(want); got != sc {
+			t.Errorf("spawn %d: g.Component(%q) = %v, want %v", i, want, got, sc)
+		}
+		if got := g.Parent(sc); got != parent {
+			t.Errorf("spawn %d: g.Parent(instance) = %v, want %v", i, got, parent)
+		}
+	}
+	for _, id := range []string{"part#1", "part#2"} {
+		if g.Component(id) == nil {
+			t.Errorf("g.Component(%q) = nil, want a SolidRect", id)
+		}
+	}
+
+	// The template is unchanged, and not part of the game.
+	if got, want := prefab.Template.(*Scene).Ident(), "thing"; got != want {
+		t.Errorf("template Ident() = %q, want %q", got, want)
+	}
+	if c := g.Component("thing"); c != nil {
+		t.Errorf("g.Component(thing) = %v, want nil", c)
+	}
+
+	if _, err := prefab.New(map[string]any{"Nope": 1}); err == nil {
+		t.Error("prefab.New(bad override) = nil error, want error")
+	}
+}
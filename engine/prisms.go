@@ -20,6 +20,7 @@ import (
 	"encoding/gob"
 	"fmt"
 	"image"
+	"sort"
 
 	"github.com/DrJosh9000/ichigo/geom"
 	"github.com/hajimehoshi/ebiten/v2"
@@ -34,13 +35,21 @@ var (
 		Prepper
 		Scanner
 		Transformer
+		Unloader
 	} = &PrismMap{}
 
 	_ interface {
 		BoundingBoxer
 		Drawer
+		MoveNotifier
 		Transformer
+		XRayOccluder
 	} = &Prism{}
+
+	_ interface {
+		DrawBoxer
+		XRayOccluder
+	} = &cachedPrisms{}
 )
 
 func init() {
@@ -49,6 +58,11 @@ func init() {
 }
 
 // PrismMap is a generalised 3D tilemap/wallmap/voxelmap etc.
+//
+// If Cached, the prisms are pre-rendered together and drawn as a single
+// DrawBoxer (with the bounding box of all the prisms), instead of
+// individually. This is much faster for large static maps, but other
+// components can then only be drawn entirely in front of or behind the map.
 type PrismMap struct {
 	ID
 	Disables
@@ -60,10 +74,13 @@ type PrismMap struct {
 	PrismSize  geom.Int3            // in world voxelspace units
 	PrismTop   []image.Point        // polygon vertices anticlockwise, Y means Z
 	Sheet      Sheet
+	Cached     bool    // pre-render prisms; call Invalidate after editing Map
+	XRayAlpha  float64 // alpha of prisms covering an XRayTarget; zero means the target decides
 
+	cached    *cachedPrisms
 	game      *Game
 	pwinverse geom.RatMatrix3
-	topext    [4]image.Point
+	outline   prismOutline
 }
 
 // CollidesWith checks if the box collides with any prism.
@@ -124,15 +141,32 @@ func (m *PrismMap) Prepare(g *Game) error {
 		p.pos = m.PosToWorld.Apply(v)
 		p.m = m
 	}
-	m.topext = geom.PolygonExtrema(m.PrismTop)
+	m.outline = newPrismOutline(m.PrismTop, g.Projection)
 	return nil
 }
 
-// Scan visits &m.Sheet and all Prisms.
+// Invalidate discards the cache. Call this after changing Map.
+func (m *PrismMap) Invalidate() {
+	if m.cached != nil {
+		m.cached.invalidate()
+	}
+}
+
+// Unload disposes of the cached images.
+func (m *PrismMap) Unload() { m.Invalidate() }
+
+// Scan visits &m.Sheet and all Prisms (or the cache of all prisms, if
+// Cached).
 func (m *PrismMap) Scan(visit VisitFunc) error {
 	if err := visit(&m.Sheet); err != nil {
 		return err
 	}
+	if m.Cached {
+		if m.cached == nil {
+			m.cached = &cachedPrisms{m: m}
+		}
+		return visit(m.cached)
+	}
 	for _, prism := range m.Map {
 		if err := visit(prism); err != nil {
 			return err
@@ -169,28 +203,20 @@ func (p *Prism) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
 
 // DrawAfter reports if the prism should be drawn after x.
 func (p *Prism) DrawAfter(x Drawer) bool {
-	pb := p.BoundingBox()
 	switch x := x.(type) {
 	case *Prism:
 		// Fast path for other prisms
-		if p.pos.Z == x.pos.Z {
-			// TODO: account for projection
-			return p.pos.Y < x.pos.Y
-		}
-		return p.pos.Z > x.pos.Z
+		return prismLess(x, p, p.m.game.Projection.Sign())
 
 	case BoundingBoxer:
+		// The prism special: x is drawn after the back half of the prism,
+		// and before the front half.
 		xb := x.BoundingBox()
-		// The prism special
-		split := p.m.topext[geom.North].X
-		threshold := p.m.topext[geom.East].Y
-		if xb.Min.X > split {
-			threshold = p.m.topext[geom.West].Y
-		}
-		if pb.Min.Z+threshold <= xb.Min.Z { // x is in front of the front half of p
+		front := p.frontZ(xb)
+		if front <= xb.Min.Z { // x is in front of the front half of p
 			return false
 		}
-		if pb.Min.Z+threshold >= xb.Max.Z { // x is behind the front half of p
+		if front >= xb.Max.Z { // x is behind the front half of p
 			return true
 		}
 	}
@@ -199,34 +225,41 @@ func (p *Prism) DrawAfter(x Drawer) bool {
 
 // DrawBefore reports if the prism should be drawn before x.
 func (p *Prism) DrawBefore(x Drawer) bool {
-	pb := p.BoundingBox()
 	switch x := x.(type) {
 	case *Prism:
 		// Fast path for other prisms
-		if p.pos.Z == x.pos.Z {
-			// TODO: account for projection
-			return p.pos.Y > x.pos.Y
-		}
-		return p.pos.Z < x.pos.Z
+		return prismLess(p, x, p.m.game.Projection.Sign())
 
 	case BoundingBoxer:
+		// The prism special (see DrawAfter).
 		xb := x.BoundingBox()
-		// The prism special
-		split := p.m.topext[geom.North].X
-		threshold := p.m.topext[geom.East].Y
-		if xb.Min.X > split {
-			threshold = p.m.topext[geom.West].Y
-		}
-		if pb.Min.Z+threshold >= xb.Max.Z { // x is behind the front half of p
+		front := p.frontZ(xb)
+		if front >= xb.Max.Z { // x is behind the front half of p
 			return false
 		}
-		if pb.Min.Z+threshold <= xb.Min.Z { // x is in front of the front half of p
+		if front <= xb.Min.Z { // x is in front of the front half of p
 			return true
 		}
 	}
 	return false
 }
 
+// frontZ returns the Z coordinate that divides the back half of the prism from
+// the front half, on the side of the prism (as seen on screen) nearest xb.
+func (p *Prism) frontZ(xb geom.Box) int {
+	o := &p.m.outline
+	// Compare the screen X of x with the screen X of the back of the prism.
+	rel := xb.Min.Sub(p.pos)
+	if rel.X+p.m.game.Projection.Project(rel.Z).X > o.screenX(o.back) {
+		return p.pos.Z + o.right.Y
+	}
+	return p.pos.Z + o.left.Y
+}
+
+// NotifiesMoves returns true: prisms never move, so there is nothing to
+// report.
+func (Prism) NotifiesMoves() bool { return true }
+
 func (p *Prism) String() string {
 	return fmt.Sprintf("Prism(%d)@%v", p.Cell, p.pos)
 }
@@ -238,3 +271,155 @@ func (p *Prism) Transform() (opts ebiten.DrawImageOptions) {
 	))
 	return opts
 }
+
+// XRayAlpha returns the XRayAlpha of the map.
+func (p *Prism) XRayAlpha() float64 { return p.m.XRayAlpha }
+
+// prismLess reports if prism p must be drawn before prism q, given the sign
+// of the projection. Prisms with separated bounding boxes are ordered the same
+// way as drawOrderConstraint. Otherwise prisms nearer the front (+Z) are drawn
+// later, followed by prisms nearer the viewer in Y, then X (in the opposite
+// direction to πsign).
+func prismLess(p, q *Prism, πsign image.Point) bool {
+	d := p.pos.Sub(q.pos)
+	size := p.m.PrismSize
+	switch {
+	case abs(d.Z) >= size.Z:
+		return d.Z < 0
+	case πsign.X != 0 && abs(d.X) >= size.X:
+		return d.X*πsign.X > 0
+	case πsign.Y != 0 && abs(d.Y) >= size.Y:
+		return d.Y*πsign.Y > 0
+	}
+	if d.Z != 0 {
+		return d.Z < 0
+	}
+	if dy := d.Y * πsign.Y; dy != 0 {
+		return dy > 0
+	}
+	return d.X*πsign.X > 0
+}
+
+// prismOutline holds the vertices of a prism top that matter for draw
+// ordering under a given projection.
+type prismOutline struct {
+	π           geom.Projector
+	left, right image.Point // furthest left and right on screen
+	back        image.Point // furthest back (least Z)
+}
+
+// newPrismOutline finds the outline of the top polygon (where Y means Z).
+func newPrismOutline(top []image.Point, π geom.Projector) prismOutline {
+	o := prismOutline{π: π}
+	for i, v := range top {
+		if i == 0 {
+			o.left, o.right, o.back = v, v, v
+			continue
+		}
+		if o.screenX(v) < o.screenX(o.left) {
+			o.left = v
+		}
+		if o.screenX(v) > o.screenX(o.right) {
+			o.right = v
+		}
+		if v.Y < o.back.Y {
+			o.back = v
+		}
+	}
+	return o
+}
+
+// screenX returns the projected X coordinate of a top vertex.
+func (o *prismOutline) screenX(v image.Point) int {
+	return v.X + o.π.Project(v.Y).X
+}
+
+// cachedPrisms draws all the prisms in a PrismMap from a cache.
+type cachedPrisms struct {
+	m *PrismMap
+
+	cache  chunkCache
+	valid  bool
+	prisms []*Prism // in draw order
+	box    geom.Box
+	bounds image.Rectangle
+}
+
+// BoundingBox returns a box containing every prism.
+func (c *cachedPrisms) BoundingBox() geom.Box {
+	c.update()
+	return c.box
+}
+
+// Draw draws the prisms from the cache.
+func (c *cachedPrisms) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
+	c.update()
+	c.cache.draw(screen, opts, c.bounds, c.renderChunk)
+}
+
+func (c *cachedPrisms) String() string { return "PrismMap cache" }
+
+// XRayAlpha returns the XRayAlpha of the map.
+func (c *cachedPrisms) XRayAlpha() float64 { return c.m.XRayAlpha }
+
+func (c *cachedPrisms) invalidate() {
+	c.cache.invalidate()
+	c.valid = false
+}
+
+// update sorts the prisms and computes bounds, if needed.
+func (c *cachedPrisms) update() {
+	if c.valid {
+		return
+	}
+	c.prisms = c.prisms[:0]
+	c.box, c.bounds = geom.Box{}, image.Rectangle{}
+	for _, p := range c.m.Map {
+		c.prisms = append(c.prisms, p)
+		c.bounds = c.bounds.Union(c.rect(p))
+		c.box = c.box.Union(p.BoundingBox())
+	}
+	πsign := c.m.game.Projection.Sign()
+	sort.Slice(c.prisms, func(i, j int) bool {
+		p, q := c.prisms[i], c.prisms[j]
+		if prismLess(p, q, πsign) {
+			return true
+		}
+		if prismLess(q, p, πsign) {
+			return false
+		}
+		if p.pos.X != q.pos.X {
+			return p.pos.X < q.pos.X
+		}
+		return p.pos.Y < q.pos.Y
+	})
+	c.valid = true
+}
+
+// rect returns the rectangle the prism draws to, in map coordinates.
+func (c *cachedPrisms) rect(p *Prism) image.Rectangle {
+	min := geom.Project(c.m.game.Projection, p.pos)
+	return image.Rectangle{min, min.Add(c.m.Sheet.CellSize)}
+}
+
+// renderChunk draws the prisms overlapping a cache chunk.
+func (c *cachedPrisms) renderChunk(dst *ebiten.Image, origin image.Point) {
+	chunk := image.Rect(0, 0, cacheChunkSize, cacheChunkSize).Add(origin)
+	var opts ebiten.DrawImageOptions
+	for _, p := range c.prisms {
+		r := c.rect(p)
+		if !r.Overlaps(chunk) {
+			continue
+		}
+		opts.GeoM.Reset()
+		opts.GeoM.Translate(geom.CFloat(r.Min.Sub(origin)))
+		dst.DrawImage(c.m.Sheet.SubImage(p.Cell), &opts)
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"image"
+	"testing"
+
+	"github.com/DrJosh9000/ichigo/geom"
+	"github.com/google/go-cmp/cmp"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type fakeBoxDrawer geom.Box
+
+func (fakeBoxDrawer) Draw(*ebiten.Image, *ebiten.DrawImageOptions) {}
+func (b fakeBoxDrawer) BoundingBox() geom.Box                      { return geom.Box(b) }
+
+// hexPrismTop is the same as the hexagonal prisms in the example.
+var hexPrismTop = []image.Point{
+	{X: 8, Y: 0},
+	{X: 0, Y: 8},
+	{X: 8, Y: 16},
+	{X: 23, Y: 16},
+	{X: 31, Y: 8},
+	{X: 23, Y: 0},
+}
+
+// newTestPrismMap makes a prepared 3x2x3 map of hexagonal prisms.
+func newTestPrismMap(t *testing.T, π geom.Projector) *PrismMap {
+	t.Helper()
+	m := &PrismMap{
+		Map: make(map[geom.Int3]*Prism),
+		PosToWorld: geom.IntMatrix3x4{
+			0: [4]int{24, 0, 0, 0},
+			1: [4]int{0, 16, 0, 0},
+			2: [4]int{8, 0, 16, 0},
+		},
+		PrismSize: geom.Int3{X: 32, Y: 16, Z: 16},
+		PrismTop:  hexPrismTop,
+	}
+	for x := 0; x < 3; x++ {
+		for y := 0; y < 2; y++ {
+			for z := 0; z < 3; z++ {
+				m.Map[geom.Pt3(x, y, z)] = &Prism{}
+			}
+		}
+	}
+	if err := m.Prepare(&Game{Projection: π}); err != nil {
+		t.Fatalf("PrismMap.Prepare(Game) = %v", err)
+	}
+	return m
+}
+
+var testProjections = []geom.Projector{
+	geom.ElevationProjection{},
+	geom.SimpleProjection{},
+	geom.IntProjection{X: 2, Y: 1},
+	geom.IntProjection{X: -2, Y: 1},
+	geom.Projection{X: 0.5, Y: 1},
+	geom.Projection{X: -0.5, Y: 1},
+}
+
+func TestPrismLess(t *testing.T) {
+	tests := []struct {
+		π    geom.Projector
+		p, q geom.Int3 // map positions
+		want bool
+	}{
+		// Prisms behind (-Z) are drawn first under every projection.
+		{geom.ElevationProjection{}, geom.Pt3(0, 0, 0), geom.Pt3(0, 0, 1), true},
+		{geom.SimpleProjection{}, geom.Pt3(0, 0, 0), geom.Pt3(0, 0, 1), true},
+		{geom.Projection{X: 0.5, Y: 1}, geom.Pt3(2, 0, 0), geom.Pt3(0, 0, 1), true},
+		{geom.Projection{X: 0.5, Y: 1}, geom.Pt3(0, 0, 1), geom.Pt3(2, 0, 0), false},
+
+		// Lower prisms (+Y) are drawn first when Y projects down.
+		{geom.SimpleProjection{}, geom.Pt3(0, 1, 0), geom.Pt3(0, 0, 0), true},
+		{geom.SimpleProjection{}, geom.Pt3(0, 0, 0), geom.Pt3(0, 1, 0), false},
+		{geom.IntProjection{X: 2, Y: 1}, geom.Pt3(0, 1, 0), geom.Pt3(0, 0, 0), true},
+
+		// No preference for Y when Z doesn't affect Y.
+		{geom.ElevationProjection{}, geom.Pt3(0, 1, 0), geom.Pt3(0, 0, 0), false},
+		{geom.ElevationProjection{}, geom.Pt3(0, 0, 0), geom.Pt3(0, 1, 0), false},
+
+		// Odd columns of hexagons are further forward.
+		{geom.SimpleProjection{}, geom.Pt3(0, 0, 0), geom.Pt3(1, 0, 0), true},
+		{geom.SimpleProjection{}, geom.Pt3(1, 0, 0), geom.Pt3(0, 0, 0), false},
+		{geom.Projection{X: -0.5, Y: 1}, geom.Pt3(0, 0, 0), geom.Pt3(1, 0, 0), true},
+
+		// No preference for X when Z doesn't affect X.
+		{geom.SimpleProjection{}, geom.Pt3(2, 0, 0), geom.Pt3(0, 0, 1), false},
+		{geom.SimpleProjection{}, geom.Pt3(0, 0, 1), geom.Pt3(2, 0, 0), false},
+
+		// Prisms further along the X sign are drawn first.
+		{geom.Projection{X: 0.5, Y: 1}, geom.Pt3(2, 0, 0), geom.Pt3(0, 0, 1), true},
+		{geom.Projection{X: 0.5, Y: 1}, geom.Pt3(0, 0, 1), geom.Pt3(2, 0, 0), false},
+		{geom.Projection{X: -0.5, Y: 1}, geom.Pt3(2, 0, 0), geom.Pt3(0, 0, 1), false},
+		{geom.Projection{X: -0.5, Y: 1}, geom.Pt3(0, 0, 1), geom.Pt3(2, 0, 0), true},
+		{geom.IntProjection{X: 2, Y: 1}, geom.Pt3(2, 0, 0), geom.Pt3(0, 0, 1), true},
+		{geom.IntProjection{X: -2, Y: 1}, geom.Pt3(2, 0, 0), geom.Pt3(0, 0, 1), false},
+
+		// Separation in X takes priority over separation in Y.
+		{geom.SimpleProjection{}, geom.Pt3(2, 0, 0), geom.Pt3(0, 1, 1), false},
+		{geom.Projection{X: 0.5, Y: 1}, geom.Pt3(2, 0, 0), geom.Pt3(0, 1, 1), true},
+		{geom.Projection{X: 0.5, Y: 1}, geom.Pt3(0, 1, 1), geom.Pt3(2, 0, 0), false},
+		{geom.Projection{X: -0.5, Y: 1}, geom.Pt3(2, 0, 0), geom.Pt3(0, 1, 1), false},
+		{geom.Projection{X: -0.5, Y: 1}, geom.Pt3(0, 1, 1), geom.Pt3(2, 0, 0), true},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%#v %v %v", test.π, test.p, test.q), func(t *testing.T) {
+			m := newTestPrismMap(t, test.π)
+			p, q := m.Map[test.p], m.Map[test.q]
+			if got := prismLess(p, q, test.π.Sign()); got != test.want {
+				t.Errorf("prismLess(p, q, %v) = %v, want %v", test.π.Sign(), got, test.want)
+			}
+			if got := p.DrawBefore(q); got != test.want {
+				t.Errorf("p.DrawBefore(q) = %v, want %v", got, test.want)
+			}
+			if got := q.DrawAfter(p); got != test.want {
+				t.Errorf("q.DrawAfter(p) = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPrismDrawOrderConsistent(t *testing.T) {
+	// For every pair of prisms, the constraints must not contradict one
+	// another, whether they come from the boxes or from prismLess.
+	for _, π := range testProjections {
+		t.Run(fmt.Sprintf("%#v", π), func(t *testing.T) {
+			m := newTestPrismMap(t, π)
+			for _, u := range m.Map {
+				for _, v := range m.Map {
+					if u == v {
+						continue
+					}
+					uv := drawOrderConstraint(u, v, π.Sign())
+					vu := drawOrderConstraint(v, u, π.Sign())
+					if uv != NoConstraint && vu != NoConstraint {
+						t.Errorf("prisms at %v and %v: constraint %v both ways (%v, %v)", u.pos, v.pos, uv, uv, vu)
+					}
+					if uv != NoConstraint && !prismLess(u, v, π.Sign()) {
+						t.Errorf("drawOrderConstraint(%v, %v) = %v but prismLess = false", u.pos, v.pos, uv)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPrismOutline(t *testing.T) {
+	tests := []struct {
+		π                 geom.Projector
+		left, right, back image.Point
+	}{
+		{geom.ElevationProjection{}, image.Pt(0, 8), image.Pt(31, 8), image.Pt(8, 0)},
+		{geom.SimpleProjection{}, image.Pt(0, 8), image.Pt(31, 8), image.Pt(8, 0)},
+		{geom.IntProjection{X: 1, Y: 1}, image.Pt(8, 0), image.Pt(23, 16), image.Pt(8, 0)},
+		{geom.Projection{X: 2}, image.Pt(8, 0), image.Pt(23, 16), image.Pt(8, 0)},
+		{geom.Projection{X: -2}, image.Pt(8, 16), image.Pt(23, 0), image.Pt(8, 0)},
+	}
+
+	for _, test := range tests {
+		o := newPrismOutline(hexPrismTop, test.π)
+		got := []image.Point{o.left, o.right, o.back}
+		want := []image.Point{test.left, test.right, test.back}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("newPrismOutline(hexPrismTop, %#v) diff (left, right, back):\n%s", test.π, diff)
+		}
+	}
+}
+
+func TestPrismSpecial(t *testing.T) {
+	tests := []struct {
+		π                     geom.Projector
+		box                   geom.Box
+		wantBefore, wantAfter bool
+	}{
+		// Front of the prism is Z = 8 on both sides.
+		{geom.SimpleProjection{}, geom.Box{Min: geom.Pt3(-40, 0, 10), Max: geom.Pt3(-30, 8, 14)}, true, false},
+		{geom.SimpleProjection{}, geom.Box{Min: geom.Pt3(40, 0, 10), Max: geom.Pt3(50, 8, 14)}, true, false},
+		{geom.SimpleProjection{}, geom.Box{Min: geom.Pt3(40, 0, 2), Max: geom.Pt3(50, 8, 6)}, false, true},
+		{geom.SimpleProjection{}, geom.Box{Min: geom.Pt3(40, 0, 4), Max: geom.Pt3(50, 8, 12)}, false, false},
+
+		// Projected to the right of the prism, the front is Z = 16.
+		{geom.Projection{X: 2}, geom.Box{Min: geom.Pt3(40, 0, 10), Max: geom.Pt3(50, 8, 14)}, false, true},
+		// Projected to the left of the prism, the front is Z = 0.
+		{geom.Projection{X: 2}, geom.Box{Min: geom.Pt3(-40, 0, 10), Max: geom.Pt3(-30, 8, 14)}, true, false},
+
+		// The reverse for a projection the other way.
+		{geom.Projection{X: -2}, geom.Box{Min: geom.Pt3(40, 0, 10), Max: geom.Pt3(50, 8, 14)}, true, false},
+		{geom.Projection{X: -2}, geom.Box{Min: geom.Pt3(-40, 0, 10), Max: geom.Pt3(-30, 8, 14)}, false, true},
+	}
+
+	for _, test := range tests {
+		m := newTestPrismMap(t, test.π)
+		p := m.Map[geom.Pt3(0, 0, 0)]
+		x := fakeBoxDrawer(test.box)
+		if got := p.DrawBefore(x); got != test.wantBefore {
+			t.Errorf("%#v: p.DrawBefore(%v) = %v, want %v", test.π, test.box, got, test.wantBefore)
+		}
+		if got := p.DrawAfter(x); got != test.wantAfter {
+			t.Errorf("%#v: p.DrawAfter(%v) = %v, want %v", test.π, test.box, got, test.wantAfter)
+		}
+	}
+}
+
+func TestDrawOrderConstraintNegativeSign(t *testing.T) {
+	left := fakeBoxDrawer(geom.Box{Min: geom.Pt3(0, 0, 0), Max: geom.Pt3(10, 10, 10)})
+	right := fakeBoxDrawer(geom.Box{Min: geom.Pt3(20, 0, 0), Max: geom.Pt3(30, 10, 10)})
+
+	tests := []struct {
+		πsign image.Point
+		u, v  DrawBoxer
+		want  Constraint
+	}{
+		{image.Pt(1, 0), right, left, ConstraintX},
+		{image.Pt(1, 0), left, right, NoConstraint},
+		{image.Pt(-1, 0), left, right, ConstraintX},
+		{image.Pt(-1, 0), right, left, NoConstraint},
+	}
+	for _, test := range tests {
+		if got := drawOrderConstraint(test.u, test.v, test.πsign); got != test.want {
+			t.Errorf("drawOrderConstraint(%v, %v, %v) = %v, want %v", test.u.BoundingBox(), test.v.BoundingBox(), test.πsign, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"encoding/gob"
+	"image"
+
+	"github.com/DrJosh9000/ichigo/geom"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+var _ interface {
+	Drawer
+	DrawManager
+	Hider
+	Prepper
+	Scanner
+} = &Repeat{}
+
+func init() {
+	gob.Register(&Repeat{})
+}
+
+// Repeat is a DrawManager that tiles its subtree horizontally and/or
+// vertically, enough to cover the screen. The tile size is the size of the
+// child's bounding rectangle (Child must be a BoundingRecter or
+// BoundingBoxer). It is intended for backgrounds, e.g. as the child of a
+// Parallax.
+//
+// Each tile is drawn in the same way as DrawDFS. Note that DrawDAG does not
+// draw other DrawManagers, so Repeat is not drawn if it is beneath one.
+type Repeat struct {
+	Child any
+	Hides
+	X, Y bool // repeat horizontally, vertically
+
+	game *Game
+}
+
+// Draw draws the child, repeated as needed to cover the screen.
+func (r *Repeat) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
+	tr, ok := r.tileRect()
+	if !ok || (!r.X && !r.Y) || tr.Empty() {
+		drawDFS(r.game, r, r, screen, opts)
+		return
+	}
+	vr, ok := visibleRect(screen.Bounds(), opts.GeoM)
+	if !ok {
+		drawDFS(r.game, r, r, screen, opts)
+		return
+	}
+	var min, max image.Point
+	if r.X {
+		min.X, max.X = tileRange(vr.Min.X, vr.Max.X, tr.Min.X, tr.Max.X)
+	}
+	if r.Y {
+		min.Y, max.Y = tileRange(vr.Min.Y, vr.Max.Y, tr.Min.Y, tr.Max.Y)
+	}
+	size := tr.Size()
+	for j := min.Y; j <= max.Y; j++ {
+		for i := min.X; i <= max.X; i++ {
+			var o ebiten.DrawImageOptions
+			o.GeoM.Translate(float64(i*size.X), float64(j*size.Y))
+			o = concatOpts(o, *opts)
+			drawDFS(r.game, r, r, screen, &o)
+		}
+	}
+}
+
+// ManagesDrawingSubcomponents is present so Repeat is recognised as a
+// DrawManager.
+func (*Repeat) ManagesDrawingSubcomponents() {}
+
+// Prepare saves a reference to g.
+func (r *Repeat) Prepare(g *Game) error {
+	r.game = g
+	return nil
+}
+
+// Scan visits r.Child.
+func (r *Repeat) Scan(visit VisitFunc) error {
+	return visit(r.Child)
+}
+
+func (r *Repeat) String() string { return "Repeat" }
+
+// tileRect returns the bounding rectangle of the child, if it has one.
+func (r *Repeat) tileRect() (image.Rectangle, bool) {
+	switch c := r.Child.(type) {
+	case BoundingRecter:
+		return c.BoundingRect(), true
+	case BoundingBoxer:
+		return c.BoundingBox().BoundingRect(r.game.Projection), true
+	}
+	return image.Rectangle{}, false
+}
+
+// tileRange returns the range of tile indexes (inclusive) needed to cover
+// [lo, hi), where tile i covers [min+i*(max-min), max+i*(max-min)).
+func tileRange(lo, hi, min, max int) (first, last int) {
+	w := max - min
+	return geom.FloorDiv(lo-max, w) + 1, geom.FloorDiv(hi-min-1, w)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"image"
+	"testing"
+
+	"github.com/DrJosh9000/ichigo/geom"
+	"github.com/google/go-cmp/cmp"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestTileRange(t *testing.T) {
+	tests := []struct {
+		lo, hi, min, max int
+		first, last      int
+	}{
+		{lo: 0, hi: 100, min: 0, max: 100, first: 0, last: 0},
+		{lo: 0, hi: 320, min: 0, max: 100, first: 0, last: 3},
+		{lo: -50, hi: 50, min: 0, max: 100, first: -1, last: 0},
+		{lo: 10, hi: 20, min: 25, max: 75, first: -1, last: -1},
+	}
+	for _, test := range tests {
+		first, last := tileRange(test.lo, test.hi, test.min, test.max)
+		if first != test.first || last != test.last {
+			t.Errorf("tileRange(%d, %d, %d, %d) = (%d, %d), want (%d, %d)", test.lo, test.hi, test.min, test.max, first, last, test.first, test.last)
+		}
+	}
+}
+
+func TestRepeatDraw(t *testing.T) {
+	rec := &fakeRecorder{box: geom.Box{Max: geom.Pt3(100, 100, 1)}}
+	r := &Repeat{X: true, Child: rec}
+	g := &Game{Root: r, Projection: geom.ElevationProjection{}}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v, want nil", err)
+	}
+
+	screen := ebiten.NewImage(250, 50)
+	defer screen.Dispose()
+	var opts ebiten.DrawImageOptions
+	opts.GeoM.Translate(-30, 0)
+	r.Draw(screen, &opts)
+	want := []geom.Float2{{X: -30, Y: 0}, {X: 70, Y: 0}, {X: 170, Y: 0}}
+	if diff := cmp.Diff(rec.drawn, want); diff != "" {
+		t.Errorf("drawn positions diff:\n%s", diff)
+	}
+}
+
+func TestParallaxFocalLengthThroughRepeat(t *testing.T) {
+	p := &Parallax{
+		CameraID:    "camera",
+		FocalLength: 100,
+		Child: &Repeat{X: true, Child: &Actor{
+			Pos:    geom.Pt3(0, 0, -300),
+			Bounds: geom.Box{Max: geom.Pt3(10, 10, 1)},
+		}},
+	}
+	cam := &Camera{ID: "camera", Child: p}
+	g := &Game{Root: &DrawDFS{Child: cam}}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v, want nil", err)
+	}
+	cam.Centre = image.Pt(40, 80)
+	opts := p.Transform()
+	x, y := opts.GeoM.Apply(0, 0)
+	if got, want := (geom.Float2{X: x, Y: y}), (geom.Float2{X: 30, Y: 60}); got != want {
+		t.Errorf("p.Transform() translates by %v, want %v", got, want)
+	}
+}
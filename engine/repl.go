@@ -23,7 +23,10 @@ import (
 	"io/fs"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
+
+	"github.com/DrJosh9000/ichigo/geom"
 )
 
 // REPL runs a read-evaluate-print-loop. Commands are taken from src and output
@@ -58,6 +61,10 @@ func (g *Game) REPL(src io.Reader, dst io.Writer, assets fs.FS) error {
 			g.cmdShow(dst, argv)
 		case "print":
 			g.cmdPrint(dst, argv)
+		case "spawn":
+			g.cmdSpawn(dst, argv)
+		case "dagstats":
+			g.cmdDAGStats(dst, argv)
 		}
 		fmt.Fprint(dst, prompt)
 	}
@@ -220,3 +227,68 @@ func (g *Game) cmdPrint(dst io.Writer, argv []string) {
 	}
 	fmt.Fprintf(dst, "%#v\n", c)
 }
+
+func (g *Game) cmdSpawn(dst io.Writer, argv []string) {
+	if len(argv) != 6 {
+		fmt.Fprintln(dst, "Usage: spawn PREFAB_ID PARENT_ID X Y Z")
+		return
+	}
+	p, ok := g.Component(argv[1]).(*Prefab)
+	if !ok {
+		fmt.Fprintf(dst, "Prefab %q not found\n", argv[1])
+		return
+	}
+	parent := g.Component(argv[2])
+	if parent == nil {
+		fmt.Fprintf(dst, "Component %q not found\n", argv[2])
+		return
+	}
+	var xyz [3]int
+	for i, arg := range argv[3:] {
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			fmt.Fprintf(dst, "Invalid coordinate %q: %v\n", arg, err)
+			return
+		}
+		xyz[i] = n
+	}
+	c, err := p.SpawnAt(parent, geom.Pt3(xyz[0], xyz[1], xyz[2]))
+	if err != nil {
+		fmt.Fprintf(dst, "Couldn't spawn: %v\n", err)
+		return
+	}
+	fmt.Fprintf(dst, "Spawned %v\n", c)
+}
+
+func (g *Game) cmdDAGStats(dst io.Writer, argv []string) {
+	if len(argv) > 2 {
+		fmt.Fprintln(dst, "Usage: dagstats [ID]")
+		return
+	}
+	if len(argv) == 2 {
+		d, ok := g.Component(argv[1]).(*DrawDAG)
+		if !ok {
+			fmt.Fprintf(dst, "DrawDAG %q not found\n", argv[1])
+			return
+		}
+		fmt.Fprint(dst, d.Stats())
+		return
+	}
+	noResults := true
+	g.Query(g, DrawManagerType, func(c any) error {
+		d, ok := c.(*DrawDAG)
+		if !ok {
+			return nil
+		}
+		noResults = false
+		if d.Ident() != "" {
+			fmt.Fprintf(dst, "DrawDAG %q:\n%v", d.Ident(), d.Stats())
+		} else {
+			fmt.Fprintf(dst, "DrawDAG in %v:\n%v", g.Parent(d), d.Stats())
+		}
+		return nil
+	}, nil)
+	if noResults {
+		fmt.Fprintln(dst, "No DrawDAGs")
+	}
+}
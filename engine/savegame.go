@@ -0,0 +1,139 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// SaveGameVersion is the current version of the SaveGame format. Increment it
+// whenever any Snapshotter changes the type or meaning of its snapshots, so
+// that old save games are rejected rather than restored incorrectly.
+const SaveGameVersion = 1
+
+func init() {
+	gob.Register(&SaveGame{})
+}
+
+// SaveGame is a snapshot of the dynamic state of every Snapshotter within some
+// part of the game tree. Unlike saving a whole scene (see Saver), only the
+// state that changes while the game runs is stored. Restoring a SaveGame
+// requires the scene to be loaded and prepared as usual first.
+type SaveGame struct {
+	Version int
+	Time    time.Time
+	States  map[string]any // component ID -> value returned by Snapshot
+}
+
+// Snapshot captures the state of every Snapshotter under root (including root
+// itself, if it is one).
+func (g *Game) Snapshot(root any) (*SaveGame, error) {
+	sg := &SaveGame{
+		Version: SaveGameVersion,
+		Time:    time.Now(),
+		States:  make(map[string]any),
+	}
+	err := g.Query(root, SnapshotterType, nil, func(c any) error {
+		s, ok := c.(Snapshotter)
+		if !ok {
+			return nil
+		}
+		id := s.Ident()
+		if id == "" {
+			return fmt.Errorf("snapshotter %v has no identifier", c)
+		}
+		st, err := s.Snapshot()
+		if err != nil {
+			return fmt.Errorf("snapshotting %q: %w", id, err)
+		}
+		sg.States[id] = st
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sg, nil
+}
+
+// Restore applies the states in sg to the Snapshotters under root (including
+// root itself, if it is one). Snapshotters with no saved state are left
+// alone, and saved states with no matching component are ignored.
+func (g *Game) Restore(root any, sg *SaveGame) error {
+	if sg.Version != SaveGameVersion {
+		return fmt.Errorf("save game version %d incompatible with version %d", sg.Version, SaveGameVersion)
+	}
+	return g.Query(root, SnapshotterType, nil, func(c any) error {
+		s, ok := c.(Snapshotter)
+		if !ok {
+			return nil
+		}
+		st, found := sg.States[s.Ident()]
+		if !found {
+			return nil
+		}
+		if err := s.Restore(st); err != nil {
+			return fmt.Errorf("restoring %q: %w", s.Ident(), err)
+		}
+		return nil
+	})
+}
+
+// SaveSlots stores SaveGames as numbered slots, each a gzipped gob file in a
+// directory on disk.
+type SaveSlots struct {
+	Dir string
+}
+
+// Path returns the path to the file for a slot.
+func (s SaveSlots) Path(slot int) string {
+	return filepath.Join(s.Dir, s.name(slot))
+}
+
+// Exists reports whether there is a save in the slot.
+func (s SaveSlots) Exists(slot int) bool {
+	_, err := os.Stat(s.Path(slot))
+	return err == nil
+}
+
+// Load reads the save game from a slot.
+func (s SaveSlots) Load(slot int) (*SaveGame, error) {
+	sg := new(SaveGame)
+	if err := LoadGobz(sg, os.DirFS(s.Dir), s.name(slot)); err != nil {
+		return nil, err
+	}
+	if sg.Version != SaveGameVersion {
+		return nil, fmt.Errorf("slot %d: save game version %d incompatible with version %d", slot, sg.Version, SaveGameVersion)
+	}
+	return sg, nil
+}
+
+// Save writes a save game into a slot, replacing any existing save.
+func (s SaveSlots) Save(slot int, sg *SaveGame) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+	return SaveGobz(sg, s.Path(slot))
+}
+
+func (SaveSlots) name(slot int) string {
+	return "slot" + strconv.Itoa(slot) + ".gobz"
+}
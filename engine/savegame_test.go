@@ -0,0 +1,81 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"image"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSaveSlotsRoundTrip(t *testing.T) {
+	cam := &Camera{
+		ID:       "camera",
+		Child:    fakeDrawBoxer("world"),
+		Centre:   image.Pt(12, 34),
+		Rotation: 0.5,
+		Zoom:     2,
+	}
+	g := &Game{
+		Root: &DrawDFS{Child: cam},
+	}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v, want nil", err)
+	}
+
+	sg, err := g.Snapshot(g)
+	if err != nil {
+		t.Fatalf("Snapshot(g) error = %v", err)
+	}
+	slots := SaveSlots{Dir: t.TempDir()}
+	if slots.Exists(1) {
+		t.Errorf("slots.Exists(1) = true before saving, want false")
+	}
+	if err := slots.Save(1, sg); err != nil {
+		t.Fatalf("slots.Save(1, sg) = %v, want nil", err)
+	}
+	if !slots.Exists(1) {
+		t.Errorf("slots.Exists(1) = false after saving, want true")
+	}
+
+	cam.Centre, cam.Rotation, cam.Zoom = image.Pt(0, 0), 0, 1
+
+	loaded, err := slots.Load(1)
+	if err != nil {
+		t.Fatalf("slots.Load(1) error = %v", err)
+	}
+	if err := g.Restore(g, loaded); err != nil {
+		t.Fatalf("Restore(g, loaded) = %v, want nil", err)
+	}
+	got := cameraState{Centre: cam.Centre, Rotation: cam.Rotation, Zoom: cam.Zoom}
+	want := cameraState{Centre: image.Pt(12, 34), Rotation: 0.5, Zoom: 2}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("camera after Restore diff:\n%s", diff)
+	}
+}
+
+func TestRestoreRejectsOtherVersions(t *testing.T) {
+	g := &Game{Root: fakeDrawBoxer("fake")}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v, want nil", err)
+	}
+	sg := &SaveGame{Version: SaveGameVersion + 1}
+	if err := g.Restore(g, sg); err == nil {
+		t.Errorf("Restore(g, version %d) = nil, want error", sg.Version)
+	}
+}
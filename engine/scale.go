@@ -0,0 +1,100 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"image"
+	"math"
+)
+
+// Scaling is a policy for fitting the game screen into the window.
+type Scaling int
+
+const (
+	// ScaleFit scales the screen to fit the window, preserving aspect ratio,
+	// by any factor (letterboxing as needed). This is the default.
+	ScaleFit Scaling = iota
+
+	// ScaleInteger scales the screen by the largest whole number factor that
+	// fits the window (at least 1), and letterboxes the remainder. This keeps
+	// pixel art crisp.
+	ScaleInteger
+
+	// ScaleFill scales the screen to cover the window entirely, preserving
+	// aspect ratio, and crops whatever doesn't fit.
+	ScaleFill
+
+	// ScaleExpand enlarges the logical screen to match the aspect ratio of the
+	// window, so there is no letterboxing or cropping. ScreenSize is the
+	// minimum logical screen size.
+	ScaleExpand
+)
+
+// screenLayout describes how the logical screen maps to the window.
+type screenLayout struct {
+	outside image.Point // size returned from Layout
+	logical image.Point // size of the screen that components draw onto
+	scale   float64     // from logical pixels to outside pixels
+	offset  image.Point // of the scaled logical screen, within outside
+}
+
+// direct reports whether the logical screen is the same as the ebiten screen,
+// i.e. drawing doesn't need an intermediate image.
+func (l screenLayout) direct() bool {
+	return l.outside == l.logical
+}
+
+// computeLayout applies the scaling policy to a logical screen size and an
+// outside (window) size, in device pixels.
+func computeLayout(policy Scaling, screen, outside image.Point) screenLayout {
+	l := screenLayout{
+		outside: screen,
+		logical: screen,
+		scale:   1,
+	}
+	if screen.X <= 0 || screen.Y <= 0 || outside.X <= 0 || outside.Y <= 0 {
+		return l
+	}
+	sx := float64(outside.X) / float64(screen.X)
+	sy := float64(outside.Y) / float64(screen.Y)
+
+	switch policy {
+	case ScaleInteger:
+		l.scale = math.Max(1, math.Floor(math.Min(sx, sy)))
+	case ScaleFill:
+		l.scale = math.Max(sx, sy)
+	case ScaleExpand:
+		// Ebiten does the scaling, so only the logical size changes.
+		s := math.Max(1, math.Min(sx, sy))
+		l.logical = image.Pt(
+			int(math.Max(float64(screen.X), math.Floor(float64(outside.X)/s))),
+			int(math.Max(float64(screen.Y), math.Floor(float64(outside.Y)/s))),
+		)
+		l.outside = l.logical
+		return l
+	default:
+		// Ebiten does the scaling.
+		return l
+	}
+	l.outside = outside
+	scaled := image.Pt(
+		int(math.Round(float64(screen.X)*l.scale)),
+		int(math.Round(float64(screen.Y)*l.scale)),
+	)
+	l.offset = outside.Sub(scaled).Div(2)
+	return l
+}
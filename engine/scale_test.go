@@ -0,0 +1,98 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"image"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestComputeLayout(t *testing.T) {
+	screen := image.Pt(320, 240)
+	tests := []struct {
+		name    string
+		policy  Scaling
+		outside image.Point
+		want    screenLayout
+	}{
+		{
+			name:    "fit",
+			policy:  ScaleFit,
+			outside: image.Pt(1000, 700),
+			want:    screenLayout{outside: screen, logical: screen, scale: 1},
+		},
+		{
+			name:    "integer letterbox",
+			policy:  ScaleInteger,
+			outside: image.Pt(1000, 700),
+			want: screenLayout{
+				outside: image.Pt(1000, 700),
+				logical: screen,
+				scale:   2,
+				offset:  image.Pt(180, 110),
+			},
+		},
+		{
+			name:    "integer too small",
+			policy:  ScaleInteger,
+			outside: image.Pt(300, 200),
+			want: screenLayout{
+				outside: image.Pt(300, 200),
+				logical: screen,
+				scale:   1,
+				offset:  image.Pt(-10, -20),
+			},
+		},
+		{
+			name:    "fill crops",
+			policy:  ScaleFill,
+			outside: image.Pt(640, 960),
+			want: screenLayout{
+				outside: image.Pt(640, 960),
+				logical: screen,
+				scale:   4,
+				offset:  image.Pt(-320, 0),
+			},
+		},
+		{
+			name:    "expand wide",
+			policy:  ScaleExpand,
+			outside: image.Pt(1280, 480),
+			want: screenLayout{
+				outside: image.Pt(640, 240),
+				logical: image.Pt(640, 240),
+				scale:   1,
+			},
+		},
+		{
+			name:    "expand small window",
+			policy:  ScaleExpand,
+			outside: image.Pt(100, 100),
+			want:    screenLayout{outside: screen, logical: screen, scale: 1},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := computeLayout(test.policy, screen, test.outside)
+			if diff := cmp.Diff(got, test.want, cmp.AllowUnexported(screenLayout{})); diff != "" {
+				t.Errorf("computeLayout(%v, %v, %v) diff (-got +want):\n%s", test.policy, screen, test.outside, diff)
+			}
+		})
+	}
+}
@@ -19,7 +19,6 @@ package engine
 import (
 	"encoding/gob"
 	"io/fs"
-	"path/filepath"
 )
 
 var (
@@ -74,6 +73,8 @@ type SceneRef struct {
 	Path string
 
 	*Scene // not gob encoded
+
+	assets fs.FS
 }
 
 // GobDecode saves the byte slice as Path.
@@ -94,12 +95,14 @@ func (r *SceneRef) Load(assets fs.FS) error {
 		return err
 	}
 	r.Scene = sc
+	r.assets = assets
 	return nil
 }
 
-// Save saves the scene to a file in the current directory.
+// Save saves the scene back to Path in the asset FS it was loaded from. This
+// only works if that FS is writable (see WritableFS).
 func (r *SceneRef) Save() error {
-	return SaveGobz(r.Scene, filepath.Base(r.Path))
+	return SaveGobzFS(r.Scene, r.assets, r.Path)
 }
 
 func (r *SceneRef) String() string { return "SceneRef{" + r.Path + "}" }
@@ -0,0 +1,337 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"encoding/gob"
+	"fmt"
+	"image"
+	"image/color"
+	"io/fs"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+var _ interface {
+	Disabler
+	Drawer
+	DrawManager
+	Hider
+	Identifier
+	Loader
+	Prepper
+	Scanner
+	Updater
+} = &SceneManager{}
+
+func init() {
+	gob.Register(&SceneManager{})
+}
+
+// TransitionKind chooses how SceneManager changes from one scene to the next.
+type TransitionKind int
+
+const (
+	// Cut switches to the next scene immediately.
+	Cut TransitionKind = iota
+
+	// Fade fades the current scene out to Transition.Colour, then fades the
+	// next scene in.
+	Fade
+
+	// Crossfade fades the next scene in over the top of the current scene.
+	Crossfade
+
+	// Wipe reveals the next scene from left to right.
+	Wipe
+)
+
+// Transition describes how to switch from one scene to another.
+type Transition struct {
+	Kind     TransitionKind
+	Duration int         // in ticks
+	Colour   color.Color // for Fade; nil means black
+
+	// Unload unregisters the previous scene once the transition is complete.
+	// Otherwise it is disabled and hidden, but kept, so that switching back to
+	// it is fast.
+	Unload bool
+
+	// Async loads the next scene in a separate goroutine. The transition
+	// begins once loading is complete.
+	Async bool
+}
+
+// SceneManager is a DrawManager that shows one scene at a time, and switches
+// between scenes with transitions. Scenes can be anything, but are typically
+// a *Scene or *SceneRef. Scenes being switched to are loaded, registered, and
+// prepared by Switch (if they haven't been already).
+//
+// During a transition both scenes are drawn, and both are disabled. Once the
+// transition is complete, the new scene is enabled.
+type SceneManager struct {
+	ID
+	Current any // the scene being shown
+	Disables
+	Hides
+
+	game     *Game
+	assets   fs.FS
+	retained []any // previous scenes that weren't unloaded
+
+	mu     sync.Mutex
+	loaded []pendingSwitch // from async loads, for Update to start
+
+	prev    any // scene being switched away from
+	trans   Transition
+	tick    int
+	overlay *ebiten.Image
+}
+
+type pendingSwitch struct {
+	next  any
+	trans Transition
+}
+
+// Draw draws the current scene, or both scenes during a transition.
+func (m *SceneManager) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
+	if m.prev == nil {
+		m.drawScene(m.Current, screen, opts)
+		return
+	}
+
+	p := float64(m.tick) / float64(m.trans.Duration)
+	switch m.trans.Kind {
+	case Fade:
+		// Draw the scene first, then overlay the colour, so that the scene
+		// fades as a whole.
+		scene, a := m.prev, 2*p
+		if p >= 0.5 {
+			scene, a = m.Current, 2-2*p
+		}
+		m.drawScene(scene, screen, opts)
+		c := m.trans.Colour
+		if c == nil {
+			c = color.Black
+		}
+		ov := m.offscreen(screen)
+		ov.Fill(c)
+		var o ebiten.DrawImageOptions
+		o.ColorM.Scale(1, 1, 1, a)
+		screen.DrawImage(ov, &o)
+
+	case Crossfade:
+		m.drawScene(m.prev, screen, opts)
+		ov := m.offscreen(screen)
+		ov.Clear()
+		m.drawScene(m.Current, ov, opts)
+		var o ebiten.DrawImageOptions
+		o.ColorM.Scale(1, 1, 1, p)
+		screen.DrawImage(ov, &o)
+
+	case Wipe:
+		m.drawScene(m.prev, screen, opts)
+		ov := m.offscreen(screen)
+		ov.Clear()
+		m.drawScene(m.Current, ov, opts)
+		w, h := ov.Size()
+		r := image.Rect(0, 0, int(p*float64(w)), h)
+		if !r.Empty() {
+			screen.DrawImage(ov.SubImage(r).(*ebiten.Image), nil)
+		}
+
+	default:
+		m.drawScene(m.Current, screen, opts)
+	}
+}
+
+// drawScene draws a scene (that might be nil).
+func (m *SceneManager) drawScene(scene any, screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
+	if scene == nil {
+		return
+	}
+	drawDFS(m.game, m, scene, screen, opts)
+}
+
+// offscreen returns an image the same size as screen, for drawing transitions.
+func (m *SceneManager) offscreen(screen *ebiten.Image) *ebiten.Image {
+	size := screen.Bounds().Size()
+	if m.overlay != nil {
+		if w, h := m.overlay.Size(); w == size.X && h == size.Y {
+			return m.overlay
+		}
+		m.overlay.Dispose()
+	}
+	m.overlay = ebiten.NewImage(size.X, size.Y)
+	return m.overlay
+}
+
+// Load stores a copy of assets, for loading scenes in Switch.
+func (m *SceneManager) Load(assets fs.FS) error {
+	m.assets = assets
+	return nil
+}
+
+// ManagesDrawingSubcomponents is present so SceneManager is recognised as a
+// DrawManager.
+func (*SceneManager) ManagesDrawingSubcomponents() {}
+
+// Prepare saves a reference to g.
+func (m *SceneManager) Prepare(g *Game) error {
+	m.game = g
+	return nil
+}
+
+// Scan visits m.Current, the previous scene during a transition, and any
+// previous scenes that weren't unloaded.
+func (m *SceneManager) Scan(visit VisitFunc) error {
+	if m.Current != nil {
+		if err := visit(m.Current); err != nil {
+			return err
+		}
+	}
+	if m.prev != nil {
+		if err := visit(m.prev); err != nil {
+			return err
+		}
+	}
+	return visit.Many(m.retained...)
+}
+
+// Switch begins a transition from the current scene to next. If next hasn't
+// been loaded and registered with the game, Switch does that first (in a
+// separate goroutine if t.Async). If a transition is already in progress, it
+// is completed immediately.
+func (m *SceneManager) Switch(next any, t Transition) error {
+	if next == nil {
+		return errNilComponent
+	}
+	if m.game.Parent(next) == m {
+		return m.start(next, t)
+	}
+	if t.Async {
+		go m.loadAsync(next, t)
+		return nil
+	}
+	if err := m.game.Load(next, m.assets); err != nil {
+		return fmt.Errorf("loading next scene: %w", err)
+	}
+	return m.start(next, t)
+}
+
+// loadAsync loads next, then passes it to Update to start the transition.
+func (m *SceneManager) loadAsync(next any, t Transition) {
+	startLoad := time.Now()
+	if err := m.game.Load(next, m.assets); err != nil {
+		log.Printf("SceneManager: couldn't load: %v", err)
+		return
+	}
+	log.Printf("SceneManager: finished loading in %v", time.Since(startLoad))
+	m.mu.Lock()
+	m.loaded = append(m.loaded, pendingSwitch{next: next, trans: t})
+	m.mu.Unlock()
+}
+
+// start registers and prepares next (if needed), and starts the transition.
+func (m *SceneManager) start(next any, t Transition) error {
+	if m.prev != nil {
+		m.finish()
+	}
+	if next == m.Current {
+		return nil
+	}
+	if m.game.Parent(next) != m {
+		if err := m.game.PathRegister(next, m); err != nil {
+			return fmt.Errorf("registering next scene: %w", err)
+		}
+		if err := m.game.Prepare(next); err != nil {
+			return fmt.Errorf("preparing next scene: %w", err)
+		}
+	}
+	for i, s := range m.retained {
+		if s == next {
+			m.retained = append(m.retained[:i], m.retained[i+1:]...)
+			break
+		}
+	}
+
+	if d, ok := next.(Disabler); ok {
+		d.Disable()
+	}
+	if h, ok := next.(Hider); ok {
+		h.Show()
+	}
+	if d, ok := m.Current.(Disabler); ok {
+		d.Disable()
+	}
+	m.prev, m.Current = m.Current, next
+	m.trans, m.tick = t, 0
+	if m.prev == nil || t.Kind == Cut || t.Duration <= 0 {
+		m.finish()
+	}
+	return nil
+}
+
+// finish completes the transition in progress.
+func (m *SceneManager) finish() {
+	prev := m.prev
+	m.prev = nil
+	if h, ok := prev.(Hider); ok {
+		h.Hide()
+	}
+	if prev != nil {
+		if m.trans.Unload {
+			m.game.PathUnregister(prev)
+		} else {
+			m.retained = append(m.retained, prev)
+		}
+	}
+	if d, ok := m.Current.(Disabler); ok {
+		d.Enable()
+	}
+}
+
+// Transitioning reports whether a transition is in progress.
+func (m *SceneManager) Transitioning() bool { return m.prev != nil }
+
+// Update starts transitions for asynchronously loaded scenes, and advances
+// the transition in progress.
+func (m *SceneManager) Update() error {
+	m.mu.Lock()
+	loaded := m.loaded
+	m.loaded = nil
+	m.mu.Unlock()
+	for _, p := range loaded {
+		if err := m.start(p.next, p.trans); err != nil {
+			return err
+		}
+	}
+
+	if m.prev == nil {
+		return nil
+	}
+	m.tick++
+	if m.tick >= m.trans.Duration {
+		m.finish()
+	}
+	return nil
+}
+
+func (m *SceneManager) String() string { return "SceneManager" }
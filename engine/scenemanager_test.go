@@ -0,0 +1,84 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import "testing"
+
+func TestSceneManagerSwitch(t *testing.T) {
+	a := &Scene{ID: "a", Child: MakeContainer()}
+	b := &Scene{ID: "b", Child: MakeContainer()}
+	c := &Scene{ID: "c", Child: MakeContainer()}
+	m := &SceneManager{Current: a}
+	g := &Game{Root: m}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v, want nil", err)
+	}
+
+	// Cut: b is current immediately, and a is kept but disabled and hidden.
+	if err := m.Switch(b, Transition{Kind: Cut}); err != nil {
+		t.Fatalf("m.Switch(b, Cut) = %v, want nil", err)
+	}
+	if m.Transitioning() {
+		t.Error("after cut: m.Transitioning() = true, want false")
+	}
+	if m.Current != b || b.Disabled() || b.Hidden() {
+		t.Errorf("after cut: m.Current = %v, b.Disabled() = %t, b.Hidden() = %t; want b, false, false", m.Current, b.Disabled(), b.Hidden())
+	}
+	if !a.Disabled() || !a.Hidden() {
+		t.Errorf("after cut: a.Disabled() = %t, a.Hidden() = %t, want true, true", a.Disabled(), a.Hidden())
+	}
+	if got := g.Component("b"); got != b {
+		t.Errorf("g.Component(b) = %v, want %v", got, b)
+	}
+	if got := g.Component("a"); got != a {
+		t.Errorf("g.Component(a) = %v, want %v", got, a)
+	}
+
+	// Fade with Unload: both b and c are disabled until the transition ends,
+	// then b is unregistered.
+	if err := m.Switch(c, Transition{Kind: Fade, Duration: 2, Unload: true}); err != nil {
+		t.Fatalf("m.Switch(c, Fade) = %v, want nil", err)
+	}
+	if !m.Transitioning() {
+		t.Error("during fade: m.Transitioning() = false, want true")
+	}
+	if !b.Disabled() || !c.Disabled() {
+		t.Errorf("during fade: b.Disabled() = %t, c.Disabled() = %t, want true, true", b.Disabled(), c.Disabled())
+	}
+	for i := 0; i < 2; i++ {
+		if err := g.Update(); err != nil {
+			t.Fatalf("g.Update() = %v, want nil", err)
+		}
+	}
+	if m.Transitioning() {
+		t.Error("after fade: m.Transitioning() = true, want false")
+	}
+	if c.Disabled() {
+		t.Error("after fade: c.Disabled() = true, want false")
+	}
+	if got := g.Component("b"); got != nil {
+		t.Errorf("after fade: g.Component(b) = %v, want nil", got)
+	}
+
+	// Switching back to a retained scene doesn't register it again.
+	if err := m.Switch(a, Transition{}); err != nil {
+		t.Fatalf("m.Switch(a, Cut) = %v, want nil", err)
+	}
+	if m.Current != a || a.Disabled() {
+		t.Errorf("after switching back: m.Current = %v, a.Disabled() = %t; want a, false", m.Current, a.Disabled())
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"encoding/gob"
+	"fmt"
+
+	"github.com/DrJosh9000/ichigo/geom"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Ensure Shadow satisfies interfaces.
+var _ interface {
+	DrawBoxer
+	DrawOrderer
+	Hider
+	Identifier
+	Prepper
+	Scanner
+	Transformer
+	Updater
+} = &Shadow{}
+
+func init() {
+	gob.Register(&Shadow{})
+}
+
+// Shadow draws a drop shadow on the nearest surface below (+Y) a target
+// component. The shadow shrinks as the distance to the surface increases.
+//
+// Shadow should be updated after the target, so it should be placed after the
+// target in the game tree. To sort correctly, both should be within the same
+// DrawDAG.
+type Shadow struct {
+	ID
+	Hides
+	TargetID        string   // component casting the shadow; must be a BoundingBoxer
+	CollisionDomain string   // id of component to look for surfaces inside of
+	Src             ImageRef // shadow image, centred on the surface below the target
+	MaxDistance     int      // furthest surface (in voxels) to draw on; zero means 128
+	FarScale        float64  // scale of the shadow at MaxDistance (it is 1 at 0)
+
+	game    *Game
+	target  BoundingBoxer
+	found   bool     // whether a surface was found
+	dist    int      // distance from the bottom of the target to the surface
+	surface geom.Box // footprint of the target, on top of the surface
+}
+
+// BoundingBox returns a 1-voxel high box with the footprint of the target,
+// resting on the surface.
+func (s *Shadow) BoundingBox() geom.Box { return s.surface }
+
+// Draw draws the shadow, if a surface was found and the target is not hidden.
+func (s *Shadow) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
+	if !s.visible() {
+		return
+	}
+	screen.DrawImage(s.Src.Image(), opts)
+}
+
+// DrawAfter reports false; the geometry of the shadow box is enough to place it
+// after the surface.
+func (s *Shadow) DrawAfter(Drawer) bool { return false }
+
+// DrawBefore reports if x stands on (or is above) the shadow, such as the
+// target.
+func (s *Shadow) DrawBefore(x Drawer) bool {
+	xb, ok := x.(BoundingBoxer)
+	if !ok {
+		return false
+	}
+	b := xb.BoundingBox()
+	return b.Max.Y <= s.surface.Max.Y &&
+		b.Min.X < s.surface.Max.X && b.Max.X > s.surface.Min.X &&
+		b.Min.Z < s.surface.Max.Z && b.Max.Z > s.surface.Min.Z
+}
+
+// Prepare obtains the target, and checks the collision domain exists.
+func (s *Shadow) Prepare(game *Game) error {
+	s.game = game
+	tgt, ok := game.Component(s.TargetID).(BoundingBoxer)
+	if !ok {
+		return fmt.Errorf("component %q not BoundingBoxer", s.TargetID)
+	}
+	s.target = tgt
+	if game.Component(s.CollisionDomain) == nil {
+		return fmt.Errorf("collision domain %q not found", s.CollisionDomain)
+	}
+	return s.Update()
+}
+
+// Scan visits &s.Src.
+func (s *Shadow) Scan(visit VisitFunc) error {
+	return visit(&s.Src)
+}
+
+func (s *Shadow) String() string {
+	return fmt.Sprintf("Shadow@%v", s.surface.Max)
+}
+
+// Transform centres the image on the projected surface point below the centre
+// of the target, and scales it according to the distance.
+func (s *Shadow) Transform() (opts ebiten.DrawImageOptions) {
+	if img := s.Src.Image(); img != nil {
+		w, h := img.Size()
+		opts.GeoM.Translate(-float64(w)/2, -float64(h)/2)
+	}
+	k := s.scale()
+	opts.GeoM.Scale(k, k)
+	c := s.surface.Centre()
+	c.Y = s.surface.Max.Y
+	opts.GeoM.Translate(geom.CFloat(geom.Project(s.game.Projection, c)))
+	return opts
+}
+
+// Update casts a ray downwards from the bottom of the target, to find the
+// nearest surface in the collision domain.
+func (s *Shadow) Update() error {
+	tb := s.target.BoundingBox()
+	s.found = false
+	s.surface = geom.Box{
+		Min: geom.Pt3(tb.Min.X, tb.Max.Y-1, tb.Min.Z),
+		Max: geom.Pt3(tb.Max.X, tb.Max.Y, tb.Max.Z),
+	}
+	cs := collidersIn(s.game, s.CollisionDomain)
+	if len(cs) == 0 {
+		return nil
+	}
+	for d := 0; d <= s.maxDistance(); d++ {
+		ray := s.surface.Add(geom.Pt3(0, d+1, 0))
+		for _, c := range cs {
+			if !c.CollidesWith(ray) {
+				continue
+			}
+			s.found = true
+			s.dist = d
+			s.surface = s.surface.Add(geom.Pt3(0, d, 0))
+			return nil
+		}
+	}
+	return nil
+}
+
+// visible reports if there is a shadow to draw.
+func (s *Shadow) visible() bool {
+	if !s.found {
+		return false
+	}
+	if h, ok := s.target.(Hider); ok && h.Hidden() {
+		return false
+	}
+	return true
+}
+
+// maxDistance returns MaxDistance, or its default.
+func (s *Shadow) maxDistance() int {
+	if s.MaxDistance <= 0 {
+		return 128
+	}
+	return s.MaxDistance
+}
+
+// scale returns the scale of the shadow at the current distance.
+func (s *Shadow) scale() float64 {
+	t := float64(s.dist) / float64(s.maxDistance())
+	return 1 + (s.FarScale-1)*t
+}
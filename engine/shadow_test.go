@@ -0,0 +1,110 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/DrJosh9000/ichigo/geom"
+)
+
+func TestShadowUpdate(t *testing.T) {
+	actor := &Actor{
+		Pos:    geom.Pt3(0, -20, 0),
+		Bounds: geom.Box{Min: geom.Pt3(-4, -15, -1), Max: geom.Pt3(4, 1, 1)},
+	}
+	ground := SolidRect{Box: geom.Box{Min: geom.Pt3(-100, 0, -100), Max: geom.Pt3(100, 10, 100)}}
+	g := &Game{Root: &DrawDFS{Child: &Scene{ID: "level", Child: MakeContainer(ground)}}}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v, want nil", err)
+	}
+	s := &Shadow{CollisionDomain: "level", FarScale: 0.5, MaxDistance: 38, game: g, target: actor}
+
+	tests := []struct {
+		y         int
+		found     bool
+		dist      int
+		wantBox   geom.Box
+		wantScale float64
+	}{
+		{y: -1, found: true, dist: 0, wantBox: geom.Box{Min: geom.Pt3(-4, -1, -1), Max: geom.Pt3(4, 0, 1)}, wantScale: 1},
+		{y: -20, found: true, dist: 19, wantBox: geom.Box{Min: geom.Pt3(-4, -1, -1), Max: geom.Pt3(4, 0, 1)}, wantScale: 0.75},
+		{y: -60, found: false},
+	}
+	for _, test := range tests {
+		actor.Pos.Y = test.y
+		if err := s.Update(); err != nil {
+			t.Fatalf("s.Update() = %v, want nil", err)
+		}
+		if s.found != test.found {
+			t.Errorf("at y = %d: s.found = %t, want %t", test.y, s.found, test.found)
+			continue
+		}
+		if !test.found {
+			continue
+		}
+		if s.dist != test.dist {
+			t.Errorf("at y = %d: s.dist = %d, want %d", test.y, s.dist, test.dist)
+		}
+		if got := s.BoundingBox(); got != test.wantBox {
+			t.Errorf("at y = %d: s.BoundingBox() = %v, want %v", test.y, got, test.wantBox)
+		}
+		if got := s.scale(); got != test.wantScale {
+			t.Errorf("at y = %d: s.scale() = %v, want %v", test.y, got, test.wantScale)
+		}
+		if !s.DrawBefore(&Sprite{Actor: *actor}) {
+			t.Errorf("at y = %d: s.DrawBefore(sprite) = false, want true", test.y)
+		}
+	}
+}
+
+type fakeShadowTarget struct {
+	ID
+	Hides
+	box geom.Box
+}
+
+func (f *fakeShadowTarget) BoundingBox() geom.Box { return f.box }
+
+func TestShadowPrepare(t *testing.T) {
+	target := &fakeShadowTarget{
+		ID:  "target",
+		box: geom.Box{Min: geom.Pt3(-4, -16, -1), Max: geom.Pt3(4, 0, 1)},
+	}
+	ground := SolidRect{Box: geom.Box{Min: geom.Pt3(-100, 0, -100), Max: geom.Pt3(100, 10, 100)}}
+	g := &Game{Root: &DrawDFS{Child: &Scene{ID: "level", Child: MakeContainer(target, ground)}}}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v, want nil", err)
+	}
+
+	bad := &Shadow{TargetID: "target", CollisionDomain: "nowhere"}
+	if err := bad.Prepare(g); err == nil {
+		t.Error("Prepare with missing collision domain = nil, want error")
+	}
+
+	s := &Shadow{TargetID: "target", CollisionDomain: "level"}
+	if err := s.Prepare(g); err != nil {
+		t.Fatalf("Prepare = %v, want nil", err)
+	}
+	if !s.visible() {
+		t.Error("s.visible() = false, want true")
+	}
+	target.Hide()
+	if s.visible() {
+		t.Error("with target hidden: s.visible() = true, want false")
+	}
+}
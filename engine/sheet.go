@@ -38,6 +38,7 @@ type Sheet struct {
 	Src      ImageRef
 
 	w int // width as measured in number of cells
+	n int // total number of cells
 }
 
 // NewAnim returns a new Anim for the given key, or nil if not found in
@@ -55,10 +56,11 @@ func (s *Sheet) NewAnims() map[string]*Anim {
 	return m
 }
 
-// Prepare computes the width of the image (in cells).
+// Prepare computes the width of the image (in cells), and the number of cells.
 func (s *Sheet) Prepare(*Game) error {
-	s.w, _ = s.Src.Image().Size()
-	s.w /= s.CellSize.X
+	w, h := s.Src.Image().Size()
+	s.w = w / s.CellSize.X
+	s.n = s.w * (h / s.CellSize.Y)
 	return nil
 }
 
@@ -0,0 +1,92 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"encoding/gob"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+var _ interface {
+	Drawer
+	DrawManager
+	Hider
+	Identifier
+	Prepper
+	Scanner
+} = &SplitScreen{}
+
+func init() {
+	gob.Register(&SplitScreen{})
+}
+
+// SplitScreen is a DrawManager that draws World once for each camera, into
+// each camera's viewport. This is useful for split-screen multiplayer and
+// picture-in-picture. Cameras are drawn in order, so later cameras are drawn
+// over earlier cameras.
+//
+// The cameras should have no Child, since World is drawn for them. The
+// cameras can still be controlled as usual (PointAt respects the bounds of
+// World, if it is a BoundingRecter).
+type SplitScreen struct {
+	ID
+	World   any
+	Cameras []*Camera
+	Hides
+
+	game *Game
+}
+
+// Draw draws World for each camera that isn't hidden.
+func (s *SplitScreen) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
+	for _, c := range s.Cameras {
+		if c.Hidden() {
+			continue
+		}
+		// Restrict drawing to the viewport. Drawers beneath World that cull
+		// against the screen bounds will then cull against the viewport.
+		vp := screen.SubImage(c.viewport()).(*ebiten.Image)
+		o := concatOpts(c.Transform(), *opts)
+		drawDFS(s.game, s, s.World, vp, &o)
+	}
+}
+
+// ManagesDrawingSubcomponents is present so SplitScreen is recognised as a
+// DrawManager.
+func (*SplitScreen) ManagesDrawingSubcomponents() {}
+
+// Prepare saves a reference to g.
+func (s *SplitScreen) Prepare(g *Game) error {
+	s.game = g
+	return nil
+}
+
+// Scan visits s.World and all the cameras.
+func (s *SplitScreen) Scan(visit VisitFunc) error {
+	if err := visit(s.World); err != nil {
+		return err
+	}
+	for _, c := range s.Cameras {
+		if err := visit(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SplitScreen) String() string { return "SplitScreen" }
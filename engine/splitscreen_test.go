@@ -0,0 +1,67 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"image"
+	"testing"
+
+	"github.com/DrJosh9000/ichigo/geom"
+)
+
+func TestSplitScreenCameras(t *testing.T) {
+	world := &Scene{
+		ID:     "world",
+		Bounds: Bounds(image.Rect(0, 0, 1000, 1000)),
+		Child:  MakeContainer(),
+	}
+	left := &Camera{ID: "left", Viewport: image.Rect(0, 0, 160, 240)}
+	right := &Camera{ID: "right", Viewport: image.Rect(160, 0, 320, 240)}
+	g := &Game{
+		ScreenSize: image.Pt(320, 240),
+		Root: &SplitScreen{
+			World:   world,
+			Cameras: []*Camera{left, right},
+		},
+	}
+	if err := g.LoadAndPrepare(nil); err != nil {
+		t.Fatalf("LoadAndPrepare(nil) = %v, want nil", err)
+	}
+
+	// Each camera centres on its own viewport.
+	left.Centre, left.Zoom = image.Pt(100, 100), 1
+	right.Centre, right.Zoom = image.Pt(100, 100), 1
+	for _, test := range []struct {
+		cam  *Camera
+		want image.Point
+	}{
+		{left, image.Pt(80, 120)},
+		{right, image.Pt(240, 120)},
+	} {
+		opts := test.cam.Transform()
+		x, y := opts.GeoM.Apply(100, 100)
+		if got := image.Pt(int(x), int(y)); got != test.want {
+			t.Errorf("%s: Transform maps centre to %v, want %v", test.cam.Ident(), got, test.want)
+		}
+	}
+
+	// PointAt respects the world bounds, using the viewport size.
+	left.PointAt(geom.Pt3(0, 0, 0), 1)
+	if want := image.Pt(80, 120); left.Centre != want {
+		t.Errorf("left.PointAt(origin, 1): Centre = %v, want %v", left.Centre, want)
+	}
+}
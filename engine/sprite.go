@@ -29,9 +29,11 @@ import (
 var _ interface {
 	BoundingBoxer
 	Drawer
+	MoveNotifier
 	Scanner
 	Transformer
 	Updater
+	XRayTarget
 } = &Sprite{}
 
 func init() {
@@ -39,11 +41,21 @@ func init() {
 }
 
 // Sprite combines an Actor with the ability to Draw from a single spritesheet.
+// DrawOffset positions the cell relative to the projected Actor.Pos, and the
+// Appearance adjustments pivot about the projected Actor.Pos (so flipping a
+// sprite mirrors its DrawOffset).
 type Sprite struct {
-	Actor      Actor
+	Actor Actor
+	Appearance
 	DrawOffset image.Point
 	Hides
 	Sheet Sheet
+	XRay  *XRay // how to show the sprite when covered in a DrawDAG; nil means off
+
+	// NotifyMoves promises that Actor.Pos and Actor.Bounds are only changed
+	// through Actor methods (e.g. SetPos, MoveX), which report movement.
+	// Otherwise (the default), a DrawDAG checks the sprite every Update.
+	NotifyMoves bool
 
 	anim *Anim
 }
@@ -56,6 +68,9 @@ func (s *Sprite) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
 	screen.DrawImage(s.Sheet.SubImage(s.anim.Cell()), opts)
 }
 
+// NotifiesMoves returns s.NotifyMoves.
+func (s *Sprite) NotifiesMoves() bool { return s.NotifyMoves }
+
 // Scan visits &s.Actor and &s.Sheet.
 func (s *Sprite) Scan(visit VisitFunc) error {
 	return visit.Many(&s.Actor, &s.Sheet)
@@ -77,18 +92,23 @@ func (s *Sprite) String() string {
 	return fmt.Sprintf("Sprite@%v", s.Actor.Pos)
 }
 
-// Transform returns a translation by the DrawOffset and Actor.Pos projected
+// Transform returns a translation by the DrawOffset, the Appearance
+// adjustments, and a translation by Actor.Pos projected.
 func (s *Sprite) Transform() (opts ebiten.DrawImageOptions) {
+	opts.GeoM.Translate(geom.CFloat(s.DrawOffset))
+	s.Appearance.apply(&opts)
 	opts.GeoM.Translate(geom.CFloat(
 		// Reaching into Actor for a reference to Game so I don't have to
 		// implement Prepare in this file, but writing this long comment
 		// providing exposition...
-		geom.Project(s.Actor.game.Projection, s.Actor.Pos).
-			Add(s.DrawOffset),
+		geom.Project(s.Actor.game.Projection, s.Actor.Pos),
 	))
 	return opts
 }
 
+// XRayConfig returns s.XRay.
+func (s *Sprite) XRayConfig() *XRay { return s.XRay }
+
 // Update updates the Sprite's anim. anim can change a bit so we don't tell Game
 // about it, but that means it must be updated manually.
 func (s *Sprite) Update() error { return s.anim.Update() }
@@ -0,0 +1,320 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"encoding/gob"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"unicode"
+
+	"github.com/DrJosh9000/ichigo/geom"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+var (
+	_ interface {
+		Drawer
+		Hider
+		Identifier
+		Layouter
+		Prepper
+		Transformer
+	} = &Text{}
+
+	_ interface {
+		Disabler
+		Hider
+		Identifier
+		Prepper
+		Scanner
+		Updater
+	} = &DialogueBox{}
+)
+
+func init() {
+	gob.Register(&Text{})
+	gob.Register(&DialogueBox{})
+}
+
+// Alignment is the horizontal alignment of lines of text.
+type Alignment int
+
+// Alignments.
+const (
+	AlignLeft Alignment = iota
+	AlignCentre
+	AlignRight
+)
+
+// Text draws text using a BitmapFont.
+type Text struct {
+	ID
+	Hides
+	FontID string      // ID of the BitmapFont
+	Text   string      // the text to draw
+	Pos    image.Point // top-left corner
+	Width  int         // wrap width in pixels; zero means no wrapping
+	Align  Alignment   // within Width, or the widest line if Width is zero
+	Colour color.Color // tints the text; nil means no tint
+
+	font      *BitmapFont
+	highlight color.Color // overrides Colour, e.g. for focused widgets
+	revealing bool        // if true, only draw reveal runes
+	reveal    int
+
+	// Cached wrapped lines
+	lines      []string
+	linesText  string
+	linesWidth int
+}
+
+// Draw draws the text.
+func (t *Text) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
+	var o ebiten.DrawImageOptions
+	tint := t.Colour
+	if t.highlight != nil {
+		tint = t.highlight
+	}
+	if tint != nil {
+		c := color.NRGBAModel.Convert(tint).(color.NRGBA)
+		o.ColorM.Scale(float64(c.R)/255, float64(c.G)/255, float64(c.B)/255, float64(c.A)/255)
+	}
+	o.ColorM.Concat(opts.ColorM)
+	o.CompositeMode, o.Filter = opts.CompositeMode, opts.Filter
+
+	t.layout(func(r rune, p image.Point, g Glyph) {
+		o.GeoM.Reset()
+		o.GeoM.Translate(geom.CFloat(p.Add(g.Offset)))
+		o.GeoM.Concat(opts.GeoM)
+		screen.DrawImage(t.font.Sheet.SubImage(g.Cell), &o)
+	})
+}
+
+// Layout moves the text to the top-left of r.
+func (t *Text) Layout(r image.Rectangle) {
+	t.Pos = r.Min
+}
+
+// Lines returns the text, split into lines by wrapping to Width.
+func (t *Text) Lines() []string {
+	if t.lines == nil || t.linesText != t.Text || t.linesWidth != t.Width {
+		t.lines = t.font.Wrap(t.Text, t.Width)
+		t.linesText, t.linesWidth = t.Text, t.Width
+	}
+	return t.lines
+}
+
+// MinSize returns Size.
+func (t *Text) MinSize() image.Point {
+	return t.Size()
+}
+
+// Prepare finds the font.
+func (t *Text) Prepare(game *Game) error {
+	f, ok := game.Component(t.FontID).(*BitmapFont)
+	if !ok {
+		return fmt.Errorf("component %q not *BitmapFont", t.FontID)
+	}
+	t.font = f
+	return nil
+}
+
+// Size returns the size of the text when drawn: Width (or the widest line, if
+// Width is zero) by the height of all the lines.
+func (t *Text) Size() image.Point {
+	lines := t.Lines()
+	return image.Pt(t.alignWidth(lines), len(lines)*t.font.LineSpacing())
+}
+
+func (t *Text) String() string { return fmt.Sprintf("Text%q", t.Text) }
+
+// Transform returns a translation by Pos.
+func (t *Text) Transform() (opts ebiten.DrawImageOptions) {
+	opts.GeoM.Translate(geom.CFloat(t.Pos))
+	return opts
+}
+
+// alignWidth is the width used for alignment.
+func (t *Text) alignWidth(lines []string) int {
+	if t.Width > 0 {
+		return t.Width
+	}
+	w := 0
+	for _, l := range lines {
+		if m := t.font.Measure(l); m > w {
+			w = m
+		}
+	}
+	return w
+}
+
+// layout calls glyph for every visible glyph, with its pen position.
+func (t *Text) layout(glyph func(r rune, p image.Point, g Glyph)) {
+	lines := t.Lines()
+	aw := t.alignWidth(lines)
+	n := t.reveal
+	for i, line := range lines {
+		p := image.Pt(0, i*t.font.LineSpacing())
+		switch t.Align {
+		case AlignCentre:
+			p.X = (aw - t.font.Measure(line)) / 2
+		case AlignRight:
+			p.X = aw - t.font.Measure(line)
+		}
+		prev := rune(-1)
+		for _, r := range line {
+			if t.revealing {
+				if n <= 0 {
+					return
+				}
+				n--
+			}
+			if prev >= 0 {
+				p.X += t.font.Kern(prev, r)
+			}
+			prev = r
+			g, ok := t.font.Glyph(r)
+			if !ok {
+				continue
+			}
+			if !unicode.IsSpace(r) {
+				glyph(r, p, g)
+			}
+			p.X += g.Advance
+		}
+	}
+}
+
+// DialogueBox shows pages of text one at a time, revealing each page like a
+// typewriter. Pages that have too many lines are split up. Once the last page
+// is dismissed, the box hides and disables itself.
+//
+// DialogueBox only draws the text; put it in a panel (or over a Billboard) for
+// a background.
+type DialogueBox struct {
+	ID
+	Disables
+	Hides
+	Text        Text         // draws the page; Text.Text is set by DialogueBox
+	Pages       []string     // text to show
+	MaxLines    int          // lines per page; zero means no limit
+	Speed       float64      // runes revealed per tick; zero means all at once
+	AdvanceKeys []ebiten.Key // keys that call Advance
+
+	screens  []string // Pages, wrapped and split by MaxLines
+	screen   int
+	revealed float64
+}
+
+// Advance reveals the rest of the current page, if it is still being revealed,
+// or otherwise moves to the next page.
+func (d *DialogueBox) Advance() {
+	if d.Done() {
+		return
+	}
+	if d.Revealing() {
+		d.revealed = float64(runeCount(d.Text.Lines()))
+		d.Text.reveal = int(d.revealed)
+		return
+	}
+	d.screen++
+	if d.Done() {
+		d.Hide()
+		d.Disable()
+		return
+	}
+	d.showScreen()
+}
+
+// Done reports whether all pages have been shown and dismissed.
+func (d *DialogueBox) Done() bool { return d.screen >= len(d.screens) }
+
+// Prepare splits the pages up.
+func (d *DialogueBox) Prepare(*Game) error {
+	d.paginate()
+	return nil
+}
+
+// Revealing reports whether the current page is still being revealed.
+func (d *DialogueBox) Revealing() bool {
+	return !d.Done() && int(d.revealed) < runeCount(d.Text.Lines())
+}
+
+// Scan visits &d.Text.
+func (d *DialogueBox) Scan(visit VisitFunc) error {
+	return visit(&d.Text)
+}
+
+// Start replaces the pages, and starts showing them from the beginning. It
+// also enables and shows the box.
+func (d *DialogueBox) Start(pages ...string) {
+	d.Pages = pages
+	d.paginate()
+	d.Enable()
+	d.Show()
+}
+
+func (d *DialogueBox) String() string { return "DialogueBox" }
+
+// Update reveals more of the page, and checks the AdvanceKeys.
+func (d *DialogueBox) Update() error {
+	if d.Done() {
+		return nil
+	}
+	for _, k := range d.AdvanceKeys {
+		if inpututil.IsKeyJustPressed(k) {
+			d.Advance()
+			return nil
+		}
+	}
+	if d.Speed > 0 && d.Revealing() {
+		d.revealed += d.Speed
+		d.Text.reveal = int(d.revealed)
+	}
+	return nil
+}
+
+// paginate wraps and splits Pages into screens.
+func (d *DialogueBox) paginate() {
+	d.screens = d.screens[:0]
+	for _, page := range d.Pages {
+		lines := d.Text.font.Wrap(page, d.Text.Width)
+		for d.MaxLines > 0 && len(lines) > d.MaxLines {
+			d.screens = append(d.screens, strings.Join(lines[:d.MaxLines], "\n"))
+			lines = lines[d.MaxLines:]
+		}
+		d.screens = append(d.screens, strings.Join(lines, "\n"))
+	}
+	d.screen = 0
+	d.showScreen()
+}
+
+// showScreen sets the text to the current screen, and begins revealing it.
+func (d *DialogueBox) showScreen() {
+	if d.Done() {
+		d.Text.Text = ""
+		return
+	}
+	d.Text.Text = d.screens[d.screen]
+	d.revealed = 0
+	d.Text.revealing = d.Speed > 0
+	d.Text.reveal = 0
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"image"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testFont() *BitmapFont {
+	return &BitmapFont{
+		Sheet:     Sheet{CellSize: image.Pt(8, 10)},
+		FirstRune: ' ',
+		Glyphs: map[rune]Glyph{
+			'i': {Cell: int('i' - ' '), Advance: 4},
+		},
+		Kerning: map[string]int{"AV": -2},
+	}
+}
+
+func TestBitmapFontMeasureAndWrap(t *testing.T) {
+	f := testFont()
+	for _, test := range []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"AB", 16},
+		{"AV", 14},
+		{"hi", 12},
+	} {
+		if got := f.Measure(test.s); got != test.want {
+			t.Errorf("Measure(%q) = %d, want %d", test.s, got, test.want)
+		}
+	}
+
+	got := f.Wrap("the quick brown fox\njumps", 80)
+	want := []string{"the quick", "brown fox", "jumps"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Wrap diff (-got +want):\n%s", diff)
+	}
+	got = f.Wrap("supercalifragilistic is long", 80)
+	want = []string{"supercalifragilistic", "is long"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Wrap long word diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestBitmapFontGlyphFallback(t *testing.T) {
+	f := testFont()
+	f.Fallback = '?'
+	// Pretend the sheet is prepared: 16 x 6 cells, for ' ' to '\x7f'.
+	f.Sheet.w, f.Sheet.n = 16, 96
+
+	tests := []struct {
+		r      rune
+		want   Glyph
+		wantOK bool
+	}{
+		{'A', Glyph{Cell: int('A' - ' '), Advance: 8}, true},
+		{'i', Glyph{Cell: int('i' - ' '), Advance: 4}, true},
+		{'\x7f', Glyph{Cell: 95, Advance: 8}, true},
+		{'\x80', Glyph{Cell: int('?' - ' '), Advance: 8}, true},
+		{'é', Glyph{Cell: int('?' - ' '), Advance: 8}, true},
+		{'\n', Glyph{Cell: int('?' - ' '), Advance: 8}, true},
+	}
+	for _, test := range tests {
+		got, ok := f.Glyph(test.r)
+		if ok != test.wantOK {
+			t.Errorf("Glyph(%q) ok = %t, want %t", test.r, ok, test.wantOK)
+		}
+		if diff := cmp.Diff(got, test.want); diff != "" {
+			t.Errorf("Glyph(%q) diff (-got +want):\n%s", test.r, diff)
+		}
+	}
+
+	// Without a usable fallback, there is no glyph.
+	f.Fallback = 0
+	if _, ok := f.Glyph('é'); ok {
+		t.Errorf("Glyph('é') without fallback ok = true, want false")
+	}
+}
+
+func TestTextLayoutAlignment(t *testing.T) {
+	type placed struct {
+		R rune
+		P image.Point
+	}
+	tests := []struct {
+		align Alignment
+		want  []placed
+	}{
+		{AlignLeft, []placed{{'a', image.Pt(0, 0)}, {'b', image.Pt(8, 0)}, {'c', image.Pt(0, 10)}}},
+		{AlignCentre, []placed{{'a', image.Pt(12, 0)}, {'b', image.Pt(20, 0)}, {'c', image.Pt(16, 10)}}},
+		{AlignRight, []placed{{'a', image.Pt(24, 0)}, {'b', image.Pt(32, 0)}, {'c', image.Pt(32, 10)}}},
+	}
+	for _, test := range tests {
+		txt := &Text{Text: "ab\nc", Width: 40, Align: test.align, font: testFont()}
+		var got []placed
+		txt.layout(func(r rune, p image.Point, _ Glyph) {
+			got = append(got, placed{r, p})
+		})
+		if diff := cmp.Diff(got, test.want); diff != "" {
+			t.Errorf("align %d: layout diff (-got +want):\n%s", test.align, diff)
+		}
+	}
+}
+
+func TestDialogueBoxPaging(t *testing.T) {
+	d := &DialogueBox{
+		Text:     Text{Width: 40, font: testFont()},
+		Pages:    []string{"one two three four"},
+		MaxLines: 2,
+		Speed:    1,
+	}
+	d.Prepare(nil)
+	if got, want := d.Text.Text, "one\ntwo"; got != want {
+		t.Fatalf("first screen = %q, want %q", got, want)
+	}
+
+	// Reveal three runes, then skip to the end of the screen.
+	for i := 0; i < 3; i++ {
+		d.Update()
+	}
+	if got, want := d.Text.reveal, 3; got != want {
+		t.Errorf("after 3 updates: reveal = %d, want %d", got, want)
+	}
+	d.Advance()
+	if d.Revealing() {
+		t.Error("after Advance: Revealing() = true, want false")
+	}
+
+	d.Advance()
+	if got, want := d.Text.Text, "three\nfour"; got != want {
+		t.Errorf("second screen = %q, want %q", got, want)
+	}
+	d.Advance() // reveal all
+	d.Advance() // dismiss
+	if !d.Done() || !d.Hidden() || !d.Disabled() {
+		t.Errorf("at end: Done, Hidden, Disabled = %t, %t, %t; want all true", d.Done(), d.Hidden(), d.Disabled())
+	}
+}
@@ -0,0 +1,659 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"io/fs"
+	"log"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/DrJosh9000/ichigo/geom"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+var _ interface {
+	Loader
+	scener
+} = &TiledMap{}
+
+func init() {
+	gob.Register(&TiledMap{})
+}
+
+const (
+	// tiledDeepZ is the default Z extent of SolidRects made from objects.
+	tiledDeepZ = 1 << 20
+
+	// tiledGIDMask masks the flipping and rotation flags out of a Tiled
+	// global tile ID.
+	tiledGIDMask = 0x0fffffff
+)
+
+// TiledMap loads a map made with the Tiled map editor from the asset FS,
+// either in JSON (.json, .tmj) or TMX (.tmx) format. After Load, Scene is
+// usable. Only finite, orthogonal maps are supported, and tiles are never
+// flipped or rotated. Within the map:
+//
+//   - Tile layers become Tilemaps, or Walls if the layer has the bool
+//     property "wall". The bool property "ersatz" makes a layer non-solid.
+//     Each layer can only use tiles from one tileset.
+//   - Tileset animations become AnimDefs in the layer's Sheet (with keys
+//     "tile0", "tile1", etc, by tile ID) used by AnimatedTiles.
+//   - Rectangle objects become SolidRects. The int properties "minz" and
+//     "maxz" set the Z extent, otherwise they are very deep.
+//   - Point objects, and objects with the class "spawn", become spawn points
+//     (see SpawnPoint). The int property "z" sets the Z position.
+//
+// Layer and object names become IDs, so should be unique (or empty). The
+// map's "id" property, if any, becomes the ID of the Scene.
+type TiledMap struct {
+	Path string
+
+	*Scene // not gob encoded
+
+	spawns map[string]geom.Int3
+}
+
+// GobDecode saves the byte slice as Path.
+func (m *TiledMap) GobDecode(b []byte) error {
+	m.Path = string(b)
+	return nil
+}
+
+// GobEncode returns Path as a byte slice.
+func (m *TiledMap) GobEncode() ([]byte, error) {
+	return []byte(m.Path), nil
+}
+
+// Load loads and converts the map.
+func (m *TiledMap) Load(assets fs.FS) error {
+	tm := new(tiledMap)
+	if err := decodeTiled(tm, assets, m.Path); err != nil {
+		return err
+	}
+	b := &tiledBuilder{
+		tm:     tm,
+		items:  MakeContainer(),
+		spawns: make(map[string]geom.Int3),
+	}
+	if err := b.build(assets, path.Dir(m.Path)); err != nil {
+		return fmt.Errorf("loading %s: %w", m.Path, err)
+	}
+	id, _ := tm.Properties.lookup("id")
+	m.Scene = &Scene{
+		ID:     ID(id),
+		Bounds: Bounds(image.Rect(0, 0, tm.Width*tm.TileWidth, tm.Height*tm.TileHeight)),
+		Child:  b.items,
+	}
+	m.spawns = b.spawns
+	return nil
+}
+
+// SpawnPoint returns the position of the named spawn point, and whether it
+// exists.
+func (m *TiledMap) SpawnPoint(name string) (geom.Int3, bool) {
+	p, ok := m.spawns[name]
+	return p, ok
+}
+
+func (m *TiledMap) String() string { return "TiledMap{" + m.Path + "}" }
+
+// decodeTiled decodes a Tiled map or tileset file, choosing between XML and
+// JSON by file extension.
+func decodeTiled(dst any, assets fs.FS, name string) error {
+	f, err := assets.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	switch path.Ext(name) {
+	case ".tmx", ".tsx", ".xml":
+		err = xml.NewDecoder(f).Decode(dst)
+	default:
+		err = json.NewDecoder(f).Decode(dst)
+	}
+	if err != nil {
+		return fmt.Errorf("decoding %s: %w", name, err)
+	}
+	return nil
+}
+
+// tiledBuilder converts a tiledMap into components.
+type tiledBuilder struct {
+	tm       *tiledMap
+	tilesets []*tiledTileset // sorted by descending FirstGID
+	items    *Container
+	spawns   map[string]geom.Int3
+}
+
+func (b *tiledBuilder) build(assets fs.FS, dir string) error {
+	if b.tm.Orientation != "orthogonal" {
+		return fmt.Errorf("unsupported orientation %q", b.tm.Orientation)
+	}
+	if b.tm.Infinite {
+		return errors.New("infinite maps are not supported")
+	}
+	for _, ts := range b.tm.Tilesets {
+		ts.dir = dir
+		if ts.Source != "" {
+			src := path.Join(dir, ts.Source)
+			first := ts.FirstGID
+			if err := decodeTiled(ts, assets, src); err != nil {
+				return err
+			}
+			ts.FirstGID, ts.dir = first, path.Dir(src)
+		}
+		if err := ts.init(); err != nil {
+			return fmt.Errorf("tileset %q: %w", ts.Name, err)
+		}
+		b.tilesets = append(b.tilesets, ts)
+	}
+	sort.Slice(b.tilesets, func(i, j int) bool {
+		return b.tilesets[i].FirstGID > b.tilesets[j].FirstGID
+	})
+	return b.addLayers(b.tm.Layers, image.Point{}, false)
+}
+
+// tileset returns the tileset containing the global tile ID.
+func (b *tiledBuilder) tileset(gid int) *tiledTileset {
+	for _, ts := range b.tilesets {
+		if ts.FirstGID <= gid {
+			return ts
+		}
+	}
+	return nil
+}
+
+func (b *tiledBuilder) addLayers(layers []*tiledLayer, offset image.Point, hidden bool) error {
+	for _, l := range layers {
+		off := offset.Add(image.Pt(int(l.OffsetX), int(l.OffsetY)))
+		hid := hidden || (l.Visible != nil && !*l.Visible)
+		var err error
+		switch l.kind() {
+		case "tilelayer":
+			err = b.addTileLayer(l, off, hid)
+		case "objectgroup":
+			err = b.addObjectLayer(l, off)
+		case "group":
+			err = b.addLayers(l.Layers, off, hid)
+		case "imagelayer":
+			log.Printf("TiledMap: skipping image layer %q", l.Name)
+		}
+		if err != nil {
+			return fmt.Errorf("layer %q: %w", l.Name, err)
+		}
+	}
+	return nil
+}
+
+func (b *tiledBuilder) addTileLayer(l *tiledLayer, offset image.Point, hidden bool) error {
+	gids, err := l.gids()
+	if err != nil {
+		return err
+	}
+	if len(gids) != l.Width*l.Height {
+		return fmt.Errorf("got %d tiles, want %d×%d", len(gids), l.Width, l.Height)
+	}
+	var ts *tiledTileset
+	tiles := make(map[image.Point]Tile)
+	for i, gid := range gids {
+		gid &= tiledGIDMask
+		if gid == 0 {
+			continue
+		}
+		t := b.tileset(int(gid))
+		if t == nil {
+			return fmt.Errorf("no tileset contains tile %d", gid)
+		}
+		if ts == nil {
+			ts = t
+		}
+		if t != ts {
+			return fmt.Errorf("uses tilesets %q and %q, but only one is allowed", ts.Name, t.Name)
+		}
+		tiles[image.Pt(i%l.Width, i/l.Width)] = ts.tile(int(gid) - ts.FirstGID)
+	}
+	if ts == nil {
+		// Empty layer.
+		return nil
+	}
+	wall, err := l.Properties.bool("wall")
+	if err != nil {
+		return err
+	}
+	ersatz, err := l.Properties.bool("ersatz")
+	if err != nil {
+		return err
+	}
+	tileSize := image.Pt(b.tm.TileWidth, b.tm.TileHeight)
+	if wall {
+		w := &Wall{
+			ID:     ID(l.Name),
+			Ersatz: ersatz,
+			Offset: offset,
+			Sheet:  ts.sheet(),
+			// Tiled aligns oversized tiles to the bottom left of the cell.
+			UnitOffset: image.Pt(0, tileSize.Y-ts.TileHeight),
+			UnitSize:   tileSize,
+			Units:      make(map[image.Point]*WallUnit, len(tiles)),
+		}
+		for p, tile := range tiles {
+			w.Units[p] = &WallUnit{Hides: Hides(hidden), Tile: tile}
+		}
+		b.items.Add(w)
+		return nil
+	}
+	if cs := image.Pt(ts.TileWidth, ts.TileHeight); cs != tileSize {
+		return fmt.Errorf("tileset %q tile size %v differs from map tile size %v (use a wall layer instead)", ts.Name, cs, tileSize)
+	}
+	b.items.Add(&Tilemap{
+		ID:     ID(l.Name),
+		Hides:  Hides(hidden),
+		Map:    tiles,
+		Ersatz: ersatz,
+		Offset: offset,
+		Sheet:  ts.sheet(),
+	})
+	return nil
+}
+
+func (b *tiledBuilder) addObjectLayer(l *tiledLayer, offset image.Point) error {
+	for _, o := range l.Objects {
+		pos := offset.Add(image.Pt(int(math.Round(o.X)), int(math.Round(o.Y))))
+		shape := o.shape()
+		if shape == "point" || o.Class == "spawn" || o.Type == "spawn" {
+			z, err := o.Properties.int("z", 0)
+			if err != nil {
+				return fmt.Errorf("object %q: %w", o.Name, err)
+			}
+			if _, exists := b.spawns[o.Name]; exists {
+				return fmt.Errorf("duplicate spawn point %q", o.Name)
+			}
+			b.spawns[o.Name] = geom.Pt3(pos.X, pos.Y, z)
+			continue
+		}
+		if shape != "rectangle" {
+			log.Printf("TiledMap: skipping %s object %q", shape, o.Name)
+			continue
+		}
+		minz, err := o.Properties.int("minz", -tiledDeepZ)
+		if err != nil {
+			return fmt.Errorf("object %q: %w", o.Name, err)
+		}
+		maxz, err := o.Properties.int("maxz", tiledDeepZ)
+		if err != nil {
+			return fmt.Errorf("object %q: %w", o.Name, err)
+		}
+		size := image.Pt(int(math.Round(o.Width)), int(math.Round(o.Height)))
+		b.items.Add(&SolidRect{
+			ID: ID(o.Name),
+			Box: geom.Box{
+				Min: geom.Pt3(pos.X, pos.Y, minz),
+				Max: geom.Pt3(pos.X+size.X, pos.Y+size.Y, maxz),
+			},
+		})
+	}
+	return nil
+}
+
+// The types below are the subset of the Tiled JSON and TMX formats that are
+// understood. Where the formats differ, there are separate fields.
+
+type tiledMap struct {
+	Orientation string          `json:"orientation" xml:"orientation,attr"`
+	Infinite    bool            `json:"infinite" xml:"infinite,attr"`
+	Width       int             `json:"width" xml:"width,attr"`
+	Height      int             `json:"height" xml:"height,attr"`
+	TileWidth   int             `json:"tilewidth" xml:"tilewidth,attr"`
+	TileHeight  int             `json:"tileheight" xml:"tileheight,attr"`
+	Properties  tiledProperties `json:"properties" xml:"properties>property"`
+	Tilesets    []*tiledTileset `json:"tilesets" xml:"tileset"`
+	Layers      []*tiledLayer   `json:"layers" xml:",any"`
+}
+
+type tiledTileset struct {
+	FirstGID   int         `json:"firstgid" xml:"firstgid,attr"`
+	Source     string      `json:"source" xml:"source,attr"`
+	Name       string      `json:"name" xml:"name,attr"`
+	TileWidth  int         `json:"tilewidth" xml:"tilewidth,attr"`
+	TileHeight int         `json:"tileheight" xml:"tileheight,attr"`
+	Spacing    int         `json:"spacing" xml:"spacing,attr"`
+	Margin     int         `json:"margin" xml:"margin,attr"`
+	Image      string      `json:"image" xml:"-"`
+	XMLImage   tmxImage    `json:"-" xml:"image"`
+	Tiles      []tiledTile `json:"tiles" xml:"tile"`
+
+	dir   string // directory containing the tileset file
+	anims map[string]*AnimDef
+}
+
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+}
+
+type tiledTile struct {
+	ID        int          `json:"id" xml:"id,attr"`
+	Animation []tiledFrame `json:"animation" xml:"animation>frame"`
+}
+
+type tiledFrame struct {
+	TileID   int `json:"tileid" xml:"tileid,attr"`
+	Duration int `json:"duration" xml:"duration,attr"` // milliseconds
+}
+
+// init checks the tileset is usable and converts the animations.
+func (ts *tiledTileset) init() error {
+	if ts.XMLImage.Source != "" {
+		ts.Image = ts.XMLImage.Source
+	}
+	if ts.Image == "" {
+		return errors.New("image collection tilesets are not supported")
+	}
+	if ts.Spacing != 0 || ts.Margin != 0 {
+		return errors.New("tilesets with spacing or margin are not supported")
+	}
+	ts.anims = make(map[string]*AnimDef)
+	for _, t := range ts.Tiles {
+		if len(t.Animation) == 0 {
+			continue
+		}
+		def := &AnimDef{Steps: make([]AnimStep, len(t.Animation))}
+		for i, f := range t.Animation {
+			// Tiled durations are in milliseconds, anim steps are in ticks.
+			ticks := int(math.Round(float64(f.Duration) * ebiten.DefaultTPS / 1000))
+			if ticks < 1 {
+				ticks = 1
+			}
+			def.Steps[i] = AnimStep{Cell: f.TileID, Duration: ticks}
+		}
+		ts.anims[tiledAnimKey(t.ID)] = def
+	}
+	return nil
+}
+
+// sheet returns a new Sheet for the tileset.
+func (ts *tiledTileset) sheet() Sheet {
+	return Sheet{
+		AnimDefs: ts.anims,
+		CellSize: image.Pt(ts.TileWidth, ts.TileHeight),
+		Src:      ImageRef{Path: path.Join(ts.dir, ts.Image)},
+	}
+}
+
+// tile returns a new Tile for the (local) tile ID.
+func (ts *tiledTileset) tile(id int) Tile {
+	if key := tiledAnimKey(id); ts.anims[key] != nil {
+		return &AnimatedTile{AnimKey: key}
+	}
+	return StaticTile(id)
+}
+
+func tiledAnimKey(id int) string { return "tile" + strconv.Itoa(id) }
+
+type tiledLayer struct {
+	XMLName    xml.Name        `json:"-"`
+	Type       string          `json:"type" xml:"-"`
+	Name       string          `json:"name" xml:"name,attr"`
+	Visible    *bool           `json:"visible" xml:"visible,attr"`
+	OffsetX    float64         `json:"offsetx" xml:"offsetx,attr"`
+	OffsetY    float64         `json:"offsety" xml:"offsety,attr"`
+	Properties tiledProperties `json:"properties" xml:"properties>property"`
+
+	// Tile layers
+	Width       int             `json:"width" xml:"width,attr"`
+	Height      int             `json:"height" xml:"height,attr"`
+	Encoding    string          `json:"encoding" xml:"-"`
+	Compression string          `json:"compression" xml:"-"`
+	Data        json.RawMessage `json:"data" xml:"-"`
+	XMLData     tmxData         `json:"-" xml:"data"`
+
+	// Object layers
+	Objects []*tiledObject `json:"objects" xml:"object"`
+
+	// Group layers
+	Layers []*tiledLayer `json:"layers" xml:",any"`
+}
+
+type tmxData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Text        string `xml:",chardata"`
+	Tiles       []struct {
+		GID uint32 `xml:"gid,attr"`
+	} `xml:"tile"`
+}
+
+// kind returns the layer type, using the JSON names.
+func (l *tiledLayer) kind() string {
+	if l.Type != "" {
+		return l.Type
+	}
+	switch l.XMLName.Local {
+	case "layer":
+		return "tilelayer"
+	case "objectgroup", "group", "imagelayer":
+		return l.XMLName.Local
+	}
+	return ""
+}
+
+// gids decodes the global tile IDs of a tile layer.
+func (l *tiledLayer) gids() ([]uint32, error) {
+	if l.XMLName.Local != "" {
+		switch d := l.XMLData; d.Encoding {
+		case "csv":
+			return parseTiledCSV(d.Text)
+		case "base64":
+			return decodeTiledBase64(d.Text, d.Compression)
+		case "":
+			gids := make([]uint32, len(d.Tiles))
+			for i, t := range d.Tiles {
+				gids[i] = t.GID
+			}
+			return gids, nil
+		default:
+			return nil, fmt.Errorf("unsupported encoding %q", d.Encoding)
+		}
+	}
+	switch l.Encoding {
+	case "", "csv":
+		var gids []uint32
+		if err := json.Unmarshal(l.Data, &gids); err != nil {
+			return nil, err
+		}
+		return gids, nil
+	case "base64":
+		var s string
+		if err := json.Unmarshal(l.Data, &s); err != nil {
+			return nil, err
+		}
+		return decodeTiledBase64(s, l.Compression)
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", l.Encoding)
+	}
+}
+
+func parseTiledCSV(s string) ([]uint32, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	gids := make([]uint32, len(fields))
+	for i, f := range fields {
+		n, err := strconv.ParseUint(f, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		gids[i] = uint32(n)
+	}
+	return gids, nil
+}
+
+func decodeTiledBase64(s, compression string) ([]uint32, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	var r io.Reader = bytes.NewReader(b)
+	switch compression {
+	case "":
+	case "zlib":
+		zr, err := zlib.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	case "gzip":
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", compression)
+	}
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw)%4 != 0 {
+		return nil, fmt.Errorf("tile data length %d is not a multiple of 4", len(raw))
+	}
+	gids := make([]uint32, len(raw)/4)
+	for i := range gids {
+		gids[i] = binary.LittleEndian.Uint32(raw[4*i:])
+	}
+	return gids, nil
+}
+
+type tiledObject struct {
+	Name       string          `json:"name" xml:"name,attr"`
+	Type       string          `json:"type" xml:"type,attr"`
+	Class      string          `json:"class" xml:"class,attr"`
+	X          float64         `json:"x" xml:"x,attr"`
+	Y          float64         `json:"y" xml:"y,attr"`
+	Width      float64         `json:"width" xml:"width,attr"`
+	Height     float64         `json:"height" xml:"height,attr"`
+	GID        uint32          `json:"gid" xml:"gid,attr"`
+	Properties tiledProperties `json:"properties" xml:"properties>property"`
+
+	Point    bool            `json:"point" xml:"-"`
+	Ellipse  bool            `json:"ellipse" xml:"-"`
+	Polygon  json.RawMessage `json:"polygon" xml:"-"`
+	Polyline json.RawMessage `json:"polyline" xml:"-"`
+	Text     json.RawMessage `json:"text" xml:"-"`
+
+	XMLPoint    *struct{} `json:"-" xml:"point"`
+	XMLEllipse  *struct{} `json:"-" xml:"ellipse"`
+	XMLPolygon  *struct{} `json:"-" xml:"polygon"`
+	XMLPolyline *struct{} `json:"-" xml:"polyline"`
+	XMLText     *struct{} `json:"-" xml:"text"`
+}
+
+// shape returns the kind of object.
+func (o *tiledObject) shape() string {
+	switch {
+	case o.Point || o.XMLPoint != nil:
+		return "point"
+	case o.Ellipse || o.XMLEllipse != nil:
+		return "ellipse"
+	case o.Polygon != nil || o.XMLPolygon != nil:
+		return "polygon"
+	case o.Polyline != nil || o.XMLPolyline != nil:
+		return "polyline"
+	case o.Text != nil || o.XMLText != nil:
+		return "text"
+	case o.GID != 0:
+		return "tile"
+	}
+	return "rectangle"
+}
+
+type tiledProperty struct {
+	Name  string     `json:"name" xml:"name,attr"`
+	Value tiledValue `json:"value" xml:"value,attr"`
+}
+
+// tiledValue holds any property value as a string. In TMX all values are
+// strings already, but in JSON they can be bools or numbers.
+type tiledValue string
+
+// UnmarshalJSON stores JSON strings as-is, and other values as literals.
+func (v *tiledValue) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*v = tiledValue(s)
+		return nil
+	}
+	*v = tiledValue(b)
+	return nil
+}
+
+type tiledProperties []tiledProperty
+
+func (ps tiledProperties) lookup(name string) (string, bool) {
+	for _, p := range ps {
+		if p.Name == name {
+			return string(p.Value), true
+		}
+	}
+	return "", false
+}
+
+// bool returns the value of a bool property, or false if it is missing.
+func (ps tiledProperties) bool(name string) (bool, error) {
+	v, ok := ps.lookup(name)
+	if !ok {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("property %q: %w", name, err)
+	}
+	return b, nil
+}
+
+// int returns the value of an int property, or def if it is missing.
+func (ps tiledProperties) int(name string, def int) (int, error) {
+	v, ok := ps.lookup(name)
+	if !ok {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("property %q: %w", name, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"image"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DrJosh9000/ichigo/geom"
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	tiledTestTileset = `{
+		"name": "tiles",
+		"image": "../images/tiles.png",
+		"tilewidth": 16,
+		"tileheight": 16,
+		"tiles": [
+			{"id": 3, "animation": [
+				{"tileid": 3, "duration": 100},
+				{"tileid": 4, "duration": 250}
+			]}
+		]
+	}`
+
+	tiledTestJSON = `{
+		"orientation": "orthogonal",
+		"width": 3,
+		"height": 2,
+		"tilewidth": 16,
+		"tileheight": 16,
+		"properties": [{"name": "id", "type": "string", "value": "level"}],
+		"tilesets": [{"firstgid": 1, "source": "tiles.tsj"}],
+		"layers": [
+			{
+				"type": "tilelayer",
+				"name": "ground",
+				"width": 3,
+				"height": 2,
+				"offsetx": 8,
+				"visible": true,
+				"data": [0, 1, 2, 4, 0, 2147483651]
+			},
+			{
+				"type": "group",
+				"name": "group",
+				"offsety": 4,
+				"visible": true,
+				"layers": [{
+					"type": "tilelayer",
+					"name": "wall",
+					"width": 3,
+					"height": 2,
+					"visible": false,
+					"properties": [
+						{"name": "wall", "type": "bool", "value": true},
+						{"name": "ersatz", "type": "bool", "value": true}
+					],
+					"encoding": "base64",
+					"compression": "zlib",
+					"data": "eJxjZIAAZijNAqUBAIAACQ=="
+				}]
+			},
+			{
+				"type": "objectgroup",
+				"name": "objects",
+				"visible": true,
+				"objects": [
+					{"name": "floor", "x": 0, "y": 28, "width": 48, "height": 4},
+					{"name": "start", "x": 5, "y": 6, "point": true,
+						"properties": [{"name": "z", "type": "int", "value": -2}]},
+					{"name": "blob", "x": 1, "y": 1, "width": 2, "height": 2, "ellipse": true}
+				]
+			}
+		]
+	}`
+
+	tiledTestTMX = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.9" orientation="orthogonal" width="3" height="2" tilewidth="16" tileheight="16" infinite="0">
+ <properties>
+  <property name="id" value="level"/>
+ </properties>
+ <tileset firstgid="1" name="tiles" tilewidth="16" tileheight="16">
+  <image source="../images/tiles.png" width="80" height="16"/>
+  <tile id="3">
+   <animation>
+    <frame tileid="3" duration="100"/>
+    <frame tileid="4" duration="250"/>
+   </animation>
+  </tile>
+ </tileset>
+ <layer name="ground" width="3" height="2" offsetx="8">
+  <data encoding="csv">
+0,1,2,
+4,0,2147483651
+</data>
+ </layer>
+ <group name="group" offsety="4">
+  <layer name="wall" width="3" height="2" visible="0">
+   <properties>
+    <property name="wall" type="bool" value="true"/>
+    <property name="ersatz" type="bool" value="true"/>
+   </properties>
+   <data encoding="base64" compression="zlib">
+    eJxjZIAAZijNAqUBAIAACQ==
+   </data>
+  </layer>
+ </group>
+ <objectgroup name="objects">
+  <object name="floor" x="0" y="28" width="48" height="4"/>
+  <object name="start" x="5" y="6">
+   <properties>
+    <property name="z" type="int" value="-2"/>
+   </properties>
+   <point/>
+  </object>
+  <object name="blob" x="1" y="1" width="2" height="2">
+   <ellipse/>
+  </object>
+ </objectgroup>
+</map>`
+)
+
+func TestTiledMapLoad(t *testing.T) {
+	assets := fstest.MapFS{
+		"maps/level.json": {Data: []byte(tiledTestJSON)},
+		"maps/level.tmx":  {Data: []byte(tiledTestTMX)},
+		"maps/tiles.tsj":  {Data: []byte(tiledTestTileset)},
+	}
+
+	// Note the wall's tile data is 1, 0, 3, 0, 4, 0 (as little-endian
+	// uint32s, zlib-compressed, base64-encoded).
+	sheet := Sheet{
+		AnimDefs: map[string]*AnimDef{
+			"tile3": {Steps: []AnimStep{
+				{Cell: 3, Duration: 6},
+				{Cell: 4, Duration: 15},
+			}},
+		},
+		CellSize: image.Pt(16, 16),
+		Src:      ImageRef{Path: "images/tiles.png"},
+	}
+	want := []any{
+		&Tilemap{
+			ID: "ground",
+			Map: map[image.Point]Tile{
+				{1, 0}: StaticTile(0),
+				{2, 0}: StaticTile(1),
+				{0, 1}: &AnimatedTile{AnimKey: "tile3"},
+				{2, 1}: StaticTile(2),
+			},
+			Offset: image.Pt(8, 0),
+			Sheet:  sheet,
+		},
+		&Wall{
+			ID:       "wall",
+			Ersatz:   true,
+			Offset:   image.Pt(0, 4),
+			Sheet:    sheet,
+			UnitSize: image.Pt(16, 16),
+			Units: map[image.Point]*WallUnit{
+				{0, 0}: {Hides: true, Tile: StaticTile(0)},
+				{2, 0}: {Hides: true, Tile: StaticTile(2)},
+				{1, 1}: {Hides: true, Tile: &AnimatedTile{AnimKey: "tile3"}},
+			},
+		},
+		&SolidRect{
+			ID: "floor",
+			Box: geom.Box{
+				Min: geom.Pt3(0, 28, -tiledDeepZ),
+				Max: geom.Pt3(48, 32, tiledDeepZ),
+			},
+		},
+	}
+
+	for _, p := range []string{"maps/level.json", "maps/level.tmx"} {
+		t.Run(p, func(t *testing.T) {
+			m := &TiledMap{Path: p}
+			if err := m.Load(assets); err != nil {
+				t.Fatalf("Load(assets) = %v", err)
+			}
+			if got, want := m.Ident(), "level"; got != want {
+				t.Errorf("Ident() = %q, want %q", got, want)
+			}
+			if got, want := m.BoundingRect(), image.Rect(0, 0, 48, 32); got != want {
+				t.Errorf("BoundingRect() = %v, want %v", got, want)
+			}
+			got := m.Child.(*Container).items
+			if diff := cmp.Diff(got, want, cmp.AllowUnexported(AnimatedTile{}, ImageRef{}, Sheet{}, Tilemap{}, Wall{}, WallUnit{}, chunkCache{})); diff != "" {
+				t.Errorf("items diff (-got +want):\n%s", diff)
+			}
+			start, ok := m.SpawnPoint("start")
+			if !ok {
+				t.Fatal("SpawnPoint(start) not found")
+			}
+			if want := geom.Pt3(5, 6, -2); start != want {
+				t.Errorf("SpawnPoint(start) = %v, want %v", start, want)
+			}
+		})
+	}
+}
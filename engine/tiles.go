@@ -34,6 +34,7 @@ var _ interface {
 	Hider
 	Scanner
 	Transformer
+	Unloader
 } = &Tilemap{}
 
 // Ensure StaticTile and AnimatedTile satisfy Tile.
@@ -60,6 +61,12 @@ type Tilemap struct {
 	Ersatz bool                 // disables collisions ("fake wall")
 	Offset image.Point          // world coordinates
 	Sheet  Sheet
+	Cached bool // pre-render static tiles; call Invalidate after editing Map
+
+	cache      chunkCache
+	cacheValid bool
+	bounds     image.Rectangle      // of all tiles, for the cache
+	animated   map[image.Point]Tile // drawn on top of the cache
 }
 
 // CollidesWith implements Collider.
@@ -83,12 +90,38 @@ func (t *Tilemap) CollidesWith(b geom.Box) bool {
 	return false
 }
 
-// Draw draws the tilemap.
+// Draw draws the tilemap. Only tiles that could be visible are drawn. If
+// Cached, static tiles are drawn from the cache, and only animated tiles are
+// drawn individually.
 func (t *Tilemap) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
+	if !t.Cached {
+		t.drawTiles(screen, opts, t.Map)
+		return
+	}
+	if !t.cacheValid {
+		t.bounds = image.Rectangle{}
+		t.animated = make(map[image.Point]Tile)
+		for p, tile := range t.Map {
+			if tile == nil {
+				continue
+			}
+			if _, ok := tile.(*AnimatedTile); ok {
+				t.animated[p] = tile
+			}
+			t.bounds = t.bounds.Union(t.cellRect(p))
+		}
+		t.cacheValid = true
+	}
+	t.cache.draw(screen, opts, t.bounds, t.renderChunk)
+	t.drawTiles(screen, opts, t.animated)
+}
+
+// drawTiles draws the tiles that could be visible.
+func (t *Tilemap) drawTiles(screen *ebiten.Image, opts *ebiten.DrawImageOptions, tiles map[image.Point]Tile) {
 	og := opts.GeoM
-	for p, tile := range t.Map {
+	draw := func(p image.Point, tile Tile) {
 		if tile == nil {
-			continue
+			return
 		}
 		var mat ebiten.GeoM
 		mat.Translate(geom.CFloat(geom.CMul(p, t.Sheet.CellSize)))
@@ -98,8 +131,80 @@ func (t *Tilemap) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
 		src := t.Sheet.SubImage(tile.Cell())
 		screen.DrawImage(src, opts)
 	}
+	defer func() { opts.GeoM = og }()
+
+	vr, ok := visibleRect(screen.Bounds(), og)
+	if !ok {
+		for p, tile := range tiles {
+			draw(p, tile)
+		}
+		return
+	}
+	cr := t.visibleCells(vr)
+	if cr.Dx()*cr.Dy() < len(tiles) {
+		// Fewer visible cells than tiles: look up each cell.
+		var p image.Point
+		for p.Y = cr.Min.Y; p.Y < cr.Max.Y; p.Y++ {
+			for p.X = cr.Min.X; p.X < cr.Max.X; p.X++ {
+				draw(p, tiles[p])
+			}
+		}
+		return
+	}
+	for p, tile := range tiles {
+		if p.In(cr) {
+			draw(p, tile)
+		}
+	}
+}
+
+// visibleCells returns the range of tilespace coordinates that overlap the
+// visible rectangle vr (in tilemap coordinates).
+func (t *Tilemap) visibleCells(vr image.Rectangle) image.Rectangle {
+	return image.Rectangle{
+		Min: geom.CFloorDiv(vr.Min, t.Sheet.CellSize),
+		Max: geom.CFloorDiv(vr.Max.Sub(image.Pt(1, 1)), t.Sheet.CellSize).Add(image.Pt(1, 1)),
+	}
+}
+
+// renderChunk draws the static tiles overlapping a cache chunk.
+func (t *Tilemap) renderChunk(dst *ebiten.Image, origin image.Point) {
+	cs := t.Sheet.CellSize
+	min := geom.CFloorDiv(origin, cs)
+	max := geom.CFloorDiv(origin.Add(image.Pt(cacheChunkSize-1, cacheChunkSize-1)), cs)
+	var opts ebiten.DrawImageOptions
+	var p image.Point
+	for p.Y = min.Y; p.Y <= max.Y; p.Y++ {
+		for p.X = min.X; p.X <= max.X; p.X++ {
+			tile := t.Map[p]
+			if tile == nil {
+				continue
+			}
+			if _, ok := tile.(*AnimatedTile); ok {
+				continue
+			}
+			opts.GeoM.Reset()
+			opts.GeoM.Translate(geom.CFloat(geom.CMul(p, cs).Sub(origin)))
+			dst.DrawImage(t.Sheet.SubImage(tile.Cell()), &opts)
+		}
+	}
+}
+
+// cellRect returns the rectangle covered by a cell, in tilemap coordinates.
+func (t *Tilemap) cellRect(p image.Point) image.Rectangle {
+	min := geom.CMul(p, t.Sheet.CellSize)
+	return image.Rectangle{min, min.Add(t.Sheet.CellSize)}
 }
 
+// Invalidate discards the cache. Call this after changing Map directly.
+func (t *Tilemap) Invalidate() {
+	t.cache.invalidate()
+	t.cacheValid = false
+}
+
+// Unload disposes of the cached images.
+func (t *Tilemap) Unload() { t.Invalidate() }
+
 // Load instantiates animations for all AnimatedTiles.
 func (t *Tilemap) Load(fs.FS) error {
 	for _, tile := range t.Map {
@@ -139,9 +244,15 @@ func (t *Tilemap) TileAt(wc image.Point) Tile {
 	return t.Map[geom.CDiv(wc.Sub(t.Offset), t.Sheet.CellSize)]
 }
 
-// SetTileAt sets the tile at the given world coordinate.
+// SetTileAt sets the tile at the given world coordinate. If Cached, the
+// affected part of the cache is discarded.
 func (t *Tilemap) SetTileAt(wc image.Point, tile Tile) {
-	t.Map[geom.CDiv(wc.Sub(t.Offset), t.Sheet.CellSize)] = tile
+	p := geom.CDiv(wc.Sub(t.Offset), t.Sheet.CellSize)
+	t.Map[p] = tile
+	if t.Cached {
+		t.cache.invalidateRect(t.cellRect(p))
+		t.cacheValid = false
+	}
 }
 
 // TileBounds returns a rectangle describing the tile boundary for the tile
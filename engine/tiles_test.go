@@ -0,0 +1,75 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"image"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestTilemapVisibleCells(t *testing.T) {
+	tm := &Tilemap{Sheet: Sheet{CellSize: image.Pt(16, 16)}}
+	tests := []struct {
+		vr, want image.Rectangle
+	}{
+		{image.Rect(0, 0, 32, 32), image.Rect(0, 0, 2, 2)},
+		{image.Rect(1, 1, 33, 33), image.Rect(0, 0, 3, 3)},
+		// Camera left of and above the origin.
+		{image.Rect(-5, -5, 27, 27), image.Rect(-1, -1, 2, 2)},
+		{image.Rect(-16, -16, 16, 16), image.Rect(-1, -1, 1, 1)},
+		// Entirely negative view.
+		{image.Rect(-40, -40, -8, -8), image.Rect(-3, -3, 0, 0)},
+		{image.Rect(-48, -48, -16, -16), image.Rect(-3, -3, -1, -1)},
+	}
+	for _, test := range tests {
+		if got := tm.visibleCells(test.vr); got != test.want {
+			t.Errorf("visibleCells(%v) = %v, want %v", test.vr, got, test.want)
+		}
+	}
+}
+
+func TestTilemapSetTileAtInvalidatesChunk(t *testing.T) {
+	tm := &Tilemap{
+		Map:    make(map[image.Point]Tile),
+		Sheet:  Sheet{CellSize: image.Pt(16, 16)},
+		Cached: true,
+	}
+	chunks := []image.Point{{0, 0}, {1, 0}, {0, 1}, {1, 1}}
+	tm.cache.images = make(map[image.Point]*ebiten.Image)
+	for _, p := range chunks {
+		tm.cache.images[p] = ebiten.NewImage(cacheChunkSize, cacheChunkSize)
+	}
+
+	// Cell (18, 1) is within chunk (1, 0).
+	tm.SetTileAt(image.Pt(300, 20), StaticTile(1))
+	if got, want := tm.Map[image.Pt(18, 1)], StaticTile(1); got != want {
+		t.Errorf("tm.Map[(18, 1)] = %v, want %v", got, want)
+	}
+	for _, p := range chunks {
+		_, got := tm.cache.images[p]
+		if want := p != image.Pt(1, 0); got != want {
+			t.Errorf("after SetTileAt: chunk %v cached = %t, want %t", p, got, want)
+		}
+	}
+
+	tm.Unload()
+	if got := len(tm.cache.images); got != 0 {
+		t.Errorf("after Unload: len(tm.cache.images) = %d, want 0", got)
+	}
+}
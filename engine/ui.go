@@ -0,0 +1,419 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"encoding/gob"
+	"image"
+
+	"github.com/DrJosh9000/ichigo/geom"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+var (
+	_ interface {
+		Drawer
+		DrawManager
+		Hider
+		Identifier
+		Prepper
+		Scanner
+	} = &UI{}
+
+	_ interface {
+		Hider
+		Identifier
+		Layouter
+		Scanner
+	} = &Anchored{}
+
+	_ interface {
+		Drawer
+		Hider
+		Identifier
+		Layouter
+		Scanner
+	} = &NineSlice{}
+
+	_ interface {
+		Identifier
+		Layouter
+		Scanner
+	} = &Stack{}
+
+	_ interface {
+		Identifier
+		Layouter
+		Scanner
+	} = &Grid{}
+)
+
+func init() {
+	gob.Register(&UI{})
+	gob.Register(&Anchored{})
+	gob.Register(&NineSlice{})
+	gob.Register(&Stack{})
+	gob.Register(&Grid{})
+}
+
+// UI is a DrawManager for screen-space user interface elements, such as a
+// HUD or menus. UI ignores any transform (e.g. from a Camera) it is given,
+// and draws its subtree (in the same way as DrawDFS) directly in logical
+// screen coordinates.
+//
+// If Child is a Layouter, it is laid out to fill the logical screen.
+// Otherwise, if Child is a Scanner (e.g. a Container), each of its
+// subcomponents that are Layouters is laid out to fill the screen (so that,
+// for example, several Anchored elements can share the screen). This happens
+// when the screen size changes, or after Invalidate is called (e.g. because
+// some text changed size).
+type UI struct {
+	ID
+	Child any
+	Hides
+
+	game  *Game
+	size  image.Point // screen size at the last layout
+	valid bool
+}
+
+// Draw lays out the UI if needed, then draws it.
+func (u *UI) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
+	u.layout(u.game.LogicalScreenSize())
+	o := ebiten.DrawImageOptions{ColorM: opts.ColorM}
+	drawDFS(u.game, u, u, screen, &o)
+}
+
+// Invalidate causes the layout to be recomputed before the next draw.
+func (u *UI) Invalidate() { u.valid = false }
+
+// ManagesDrawingSubcomponents is present so UI is recognised as a
+// DrawManager.
+func (*UI) ManagesDrawingSubcomponents() {}
+
+// Prepare saves a reference to g.
+func (u *UI) Prepare(g *Game) error {
+	u.game = g
+	u.valid = false
+	return nil
+}
+
+// Scan visits u.Child.
+func (u *UI) Scan(visit VisitFunc) error {
+	return visit(u.Child)
+}
+
+func (u *UI) String() string { return "UI" }
+
+// layout lays out the child, if needed, for a screen of the given size.
+func (u *UI) layout(size image.Point) {
+	if u.valid && size == u.size {
+		return
+	}
+	r := image.Rectangle{Max: size}
+	if sc, ok := u.Child.(Scanner); ok && !isLayouter(u.Child) {
+		sc.Scan(func(x any) error {
+			layoutChild(x, r)
+			return nil
+		})
+	} else {
+		layoutChild(u.Child, r)
+	}
+	u.size, u.valid = size, true
+}
+
+// Anchor is a position within a rectangle.
+type Anchor int
+
+// Anchors.
+const (
+	AnchorTopLeft Anchor = iota
+	AnchorTop
+	AnchorTopRight
+	AnchorLeft
+	AnchorCentre
+	AnchorRight
+	AnchorBottomLeft
+	AnchorBottom
+	AnchorBottomRight
+)
+
+// Anchored positions its child at an edge, corner, or the centre of the
+// rectangle it is given.
+type Anchored struct {
+	ID
+	Hides
+	Anchor Anchor
+	Margin image.Point // distance from the edges anchored to
+	Size   image.Point // size of the child; zero means the child's MinSize
+	Child  any
+}
+
+// Layout positions the child within r.
+func (a *Anchored) Layout(r image.Rectangle) {
+	sz := a.Size
+	if sz == (image.Point{}) {
+		sz = minSize(a.Child)
+	}
+	var p image.Point
+	switch a.Anchor % 3 {
+	case 0:
+		p.X = r.Min.X + a.Margin.X
+	case 1:
+		p.X = r.Min.X + (r.Dx()-sz.X)/2
+	case 2:
+		p.X = r.Max.X - a.Margin.X - sz.X
+	}
+	switch a.Anchor / 3 {
+	case 0:
+		p.Y = r.Min.Y + a.Margin.Y
+	case 1:
+		p.Y = r.Min.Y + (r.Dy()-sz.Y)/2
+	case 2:
+		p.Y = r.Max.Y - a.Margin.Y - sz.Y
+	}
+	layoutChild(a.Child, image.Rectangle{p, p.Add(sz)})
+}
+
+// MinSize returns the size plus margins.
+func (a *Anchored) MinSize() image.Point {
+	sz := a.Size
+	if sz == (image.Point{}) {
+		sz = minSize(a.Child)
+	}
+	return sz.Add(a.Margin.Mul(2))
+}
+
+// Scan visits a.Child.
+func (a *Anchored) Scan(visit VisitFunc) error {
+	return visit(a.Child)
+}
+
+func (a *Anchored) String() string { return "Anchored" }
+
+// NineSlice draws a panel from nine cells of a Sheet: the corners are drawn
+// as-is, and the edges and centre are stretched to fill the rectangle. The
+// nine cells are consecutive, starting at Cell, in the order top-left, top,
+// top-right, left, centre, right, bottom-left, bottom, bottom-right (for
+// example, a 3x3 sheet). The child is laid out inside the panel.
+type NineSlice struct {
+	ID
+	Hides
+	Sheet   Sheet
+	Cell    int
+	Padding image.Point // between the panel edge and child; zero means Sheet.CellSize
+	Child   any
+
+	rect image.Rectangle
+}
+
+// Draw draws the panel.
+func (n *NineSlice) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
+	cs := n.Sheet.CellSize
+	if n.rect.Empty() || cs.X <= 0 || cs.Y <= 0 {
+		return
+	}
+	xs := [4]int{n.rect.Min.X, n.rect.Min.X + cs.X, n.rect.Max.X - cs.X, n.rect.Max.X}
+	ys := [4]int{n.rect.Min.Y, n.rect.Min.Y + cs.Y, n.rect.Max.Y - cs.Y, n.rect.Max.Y}
+	var o ebiten.DrawImageOptions
+	o.ColorM = opts.ColorM
+	o.CompositeMode, o.Filter = opts.CompositeMode, opts.Filter
+	for j := 0; j < 3; j++ {
+		for i := 0; i < 3; i++ {
+			w, h := xs[i+1]-xs[i], ys[j+1]-ys[j]
+			if w <= 0 || h <= 0 {
+				continue
+			}
+			o.GeoM.Reset()
+			o.GeoM.Scale(float64(w)/float64(cs.X), float64(h)/float64(cs.Y))
+			o.GeoM.Translate(float64(xs[i]), float64(ys[j]))
+			o.GeoM.Concat(opts.GeoM)
+			screen.DrawImage(n.Sheet.SubImage(n.Cell+3*j+i), &o)
+		}
+	}
+}
+
+// Layout stores the rectangle for the panel, and lays out the child inside.
+func (n *NineSlice) Layout(r image.Rectangle) {
+	n.rect = r
+	pad := n.padding()
+	layoutChild(n.Child, image.Rectangle{r.Min.Add(pad), r.Max.Sub(pad)})
+}
+
+// MinSize returns the child's MinSize plus padding.
+func (n *NineSlice) MinSize() image.Point {
+	return minSize(n.Child).Add(n.padding().Mul(2))
+}
+
+// Scan visits &n.Sheet and n.Child (if not nil).
+func (n *NineSlice) Scan(visit VisitFunc) error {
+	if err := visit(&n.Sheet); err != nil {
+		return err
+	}
+	if n.Child == nil {
+		return nil
+	}
+	return visit(n.Child)
+}
+
+func (n *NineSlice) String() string { return "NineSlice" }
+
+func (n *NineSlice) padding() image.Point {
+	if n.Padding == (image.Point{}) {
+		return n.Sheet.CellSize
+	}
+	return n.Padding
+}
+
+// Stack lays out items in a column (or a row, if Horizontal), each at its
+// MinSize along the stacking direction, and stretched across the other.
+type Stack struct {
+	ID
+	Horizontal bool
+	Spacing    int
+	Items      []any
+}
+
+// Layout lays out the items within r.
+func (s *Stack) Layout(r image.Rectangle) {
+	p := r.Min
+	for _, it := range s.Items {
+		ms := minSize(it)
+		if s.Horizontal {
+			layoutChild(it, image.Rect(p.X, r.Min.Y, p.X+ms.X, r.Max.Y))
+			p.X += ms.X + s.Spacing
+		} else {
+			layoutChild(it, image.Rect(r.Min.X, p.Y, r.Max.X, p.Y+ms.Y))
+			p.Y += ms.Y + s.Spacing
+		}
+	}
+}
+
+// MinSize returns the total size of the items, with spacing.
+func (s *Stack) MinSize() image.Point {
+	var sz image.Point
+	for i, it := range s.Items {
+		ms := minSize(it)
+		sp := s.Spacing
+		if i == 0 {
+			sp = 0
+		}
+		if s.Horizontal {
+			sz.X += ms.X + sp
+			if ms.Y > sz.Y {
+				sz.Y = ms.Y
+			}
+		} else {
+			sz.Y += ms.Y + sp
+			if ms.X > sz.X {
+				sz.X = ms.X
+			}
+		}
+	}
+	return sz
+}
+
+// Scan visits all the items.
+func (s *Stack) Scan(visit VisitFunc) error {
+	return visit.Many(s.Items...)
+}
+
+func (s *Stack) String() string { return "Stack" }
+
+// Grid lays out items in rows of equally sized cells, left to right then top
+// to bottom. The cell size is the largest MinSize of all the items.
+type Grid struct {
+	ID
+	Columns int         // zero means 1
+	Spacing image.Point // between cells
+	Items   []any
+}
+
+// Layout lays out the items within r.
+func (g *Grid) Layout(r image.Rectangle) {
+	cols, cell := g.columns(), g.cellSize()
+	step := cell.Add(g.Spacing)
+	for i, it := range g.Items {
+		p := r.Min.Add(geom.CMul(image.Pt(i%cols, i/cols), step))
+		layoutChild(it, image.Rectangle{p, p.Add(cell)})
+	}
+}
+
+// MinSize returns the size of all the cells, with spacing.
+func (g *Grid) MinSize() image.Point {
+	if len(g.Items) == 0 {
+		return image.Point{}
+	}
+	cols, cell := g.columns(), g.cellSize()
+	if len(g.Items) < cols {
+		cols = len(g.Items)
+	}
+	rows := (len(g.Items) + cols - 1) / cols
+	return image.Pt(
+		cols*cell.X+(cols-1)*g.Spacing.X,
+		rows*cell.Y+(rows-1)*g.Spacing.Y,
+	)
+}
+
+// Scan visits all the items.
+func (g *Grid) Scan(visit VisitFunc) error {
+	return visit.Many(g.Items...)
+}
+
+func (g *Grid) String() string { return "Grid" }
+
+func (g *Grid) cellSize() image.Point {
+	var cell image.Point
+	for _, it := range g.Items {
+		ms := minSize(it)
+		if ms.X > cell.X {
+			cell.X = ms.X
+		}
+		if ms.Y > cell.Y {
+			cell.Y = ms.Y
+		}
+	}
+	return cell
+}
+
+func (g *Grid) columns() int {
+	if g.Columns <= 0 {
+		return 1
+	}
+	return g.Columns
+}
+
+// minSize returns the MinSize of c, if it is a Layouter, or else zero.
+func minSize(c any) image.Point {
+	if l, ok := c.(Layouter); ok {
+		return l.MinSize()
+	}
+	return image.Point{}
+}
+
+// isLayouter reports whether c is a Layouter.
+func isLayouter(c any) bool {
+	_, ok := c.(Layouter)
+	return ok
+}
+
+// layoutChild calls Layout on c, if it is a Layouter.
+func layoutChild(c any, r image.Rectangle) {
+	if l, ok := c.(Layouter); ok {
+		l.Layout(r)
+	}
+}
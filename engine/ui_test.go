@@ -0,0 +1,118 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"image"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeElement is a Layouter that records its layout.
+type fakeElement struct {
+	min  image.Point
+	rect image.Rectangle
+}
+
+func (e *fakeElement) MinSize() image.Point     { return e.min }
+func (e *fakeElement) Layout(r image.Rectangle) { e.rect = r }
+
+func TestUILayout(t *testing.T) {
+	corner := &fakeElement{min: image.Pt(20, 10)}
+	a, b := &fakeElement{min: image.Pt(30, 10)}, &fakeElement{min: image.Pt(10, 15)}
+	g1, g2, g3 := &fakeElement{min: image.Pt(8, 8)}, &fakeElement{min: image.Pt(12, 4)}, &fakeElement{min: image.Pt(4, 4)}
+	ui := &UI{
+		Child: MakeContainer(
+			&Anchored{
+				Anchor: AnchorBottomRight,
+				Margin: image.Pt(4, 2),
+				Child:  corner,
+			},
+			&Anchored{
+				Anchor: AnchorCentre,
+				Child: &Stack{
+					Spacing: 5,
+					Items:   []any{a, b},
+				},
+			},
+			&Anchored{
+				Anchor: AnchorTopLeft,
+				Child: &Grid{
+					Columns: 2,
+					Spacing: image.Pt(1, 1),
+					Items:   []any{g1, g2, g3},
+				},
+			},
+		),
+	}
+	// Each of the anchors in the container is laid out over the whole screen.
+	ui.layout(image.Pt(320, 240))
+
+	tests := []struct {
+		name string
+		e    *fakeElement
+		want image.Rectangle
+	}{
+		{"corner", corner, image.Rect(296, 228, 316, 238)},
+		{"stack a", a, image.Rect(145, 105, 175, 115)},
+		{"stack b", b, image.Rect(145, 120, 175, 135)},
+		{"grid 1", g1, image.Rect(0, 0, 12, 8)},
+		{"grid 2", g2, image.Rect(13, 0, 25, 8)},
+		{"grid 3", g3, image.Rect(0, 9, 12, 17)},
+	}
+	for _, test := range tests {
+		if diff := cmp.Diff(test.e.rect, test.want); diff != "" {
+			t.Errorf("%s: rect diff (-got +want):\n%s", test.name, diff)
+		}
+	}
+
+	// Anchors follow the screen size.
+	ui.layout(image.Pt(640, 480))
+	if want := image.Rect(616, 468, 636, 478); corner.rect != want {
+		t.Errorf("after resize: corner rect = %v, want %v", corner.rect, want)
+	}
+}
+
+func TestUIRelayout(t *testing.T) {
+	e := &fakeElement{min: image.Pt(10, 10)}
+	ui := &UI{Child: &Anchored{Anchor: AnchorBottom, Child: e}}
+
+	ui.layout(image.Pt(100, 100))
+	if want := image.Rect(45, 90, 55, 100); e.rect != want {
+		t.Errorf("first layout: rect = %v, want %v", e.rect, want)
+	}
+
+	// Same size and valid: no relayout.
+	e.rect = image.Rectangle{}
+	ui.layout(image.Pt(100, 100))
+	if e.rect != (image.Rectangle{}) {
+		t.Errorf("layout with unchanged size: rect = %v, want no layout", e.rect)
+	}
+
+	ui.layout(image.Pt(200, 50))
+	if want := image.Rect(95, 40, 105, 50); e.rect != want {
+		t.Errorf("after resize: rect = %v, want %v", e.rect, want)
+	}
+
+	e.min = image.Pt(20, 20)
+	ui.Invalidate()
+	ui.layout(image.Pt(200, 50))
+	if want := image.Rect(90, 30, 110, 50); e.rect != want {
+		t.Errorf("after Invalidate: rect = %v, want %v", e.rect, want)
+	}
+}
@@ -17,7 +17,9 @@ limitations under the License.
 package engine
 
 import (
+	"fmt"
 	"image"
+	"io/fs"
 
 	"github.com/DrJosh9000/ichigo/geom"
 	"github.com/hajimehoshi/ebiten/v2"
@@ -26,10 +28,13 @@ import (
 var (
 	_ interface {
 		Collider
+		Drawer
 		Identifier
+		Loader
 		Scanner
 		Prepper
 		Transformer
+		Unloader
 	} = &Wall{}
 
 	_ interface {
@@ -45,6 +50,10 @@ var (
 // level as other components and are responsible for their own drawing, so that
 // Game can do draw ordering, e.g. hide the player character behind a wall.
 // But Wall is still responsible for collisions.
+//
+// If Cached, Wall draws all the units with static tiles itself from a
+// pre-rendered cache (giving up that draw ordering), and only units with
+// animated tiles draw themselves.
 type Wall struct {
 	ID
 	Ersatz     bool        // disables collisions ("fake wall")
@@ -53,6 +62,11 @@ type Wall struct {
 	UnitOffset image.Point // drawing offset
 	UnitSize   image.Point // tile size
 	Units      map[image.Point]*WallUnit
+	Cached     bool // pre-render static units; call Invalidate after editing
+
+	cache      chunkCache
+	cacheValid bool
+	bounds     image.Rectangle // of all units, for the cache
 }
 
 // CollidesWith implements a tilerange collosion check, similar to Tilemap.
@@ -76,6 +90,64 @@ func (w *Wall) CollidesWith(b geom.Box) bool {
 	return false
 }
 
+// Draw draws the units with static tiles from the cache, if Cached.
+func (w *Wall) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
+	if !w.Cached {
+		return
+	}
+	if !w.cacheValid {
+		w.bounds = image.Rectangle{}
+		for _, u := range w.Units {
+			w.bounds = w.bounds.Union(u.rect())
+		}
+		w.cacheValid = true
+	}
+	w.cache.draw(screen, opts, w.bounds, w.renderChunk)
+}
+
+// renderChunk draws the static units overlapping a cache chunk.
+func (w *Wall) renderChunk(dst *ebiten.Image, origin image.Point) {
+	chunk := image.Rect(0, 0, cacheChunkSize, cacheChunkSize).Add(origin)
+	var opts ebiten.DrawImageOptions
+	for _, u := range w.Units {
+		if u.Hidden() || !u.static() {
+			continue
+		}
+		r := u.rect()
+		if !r.Overlaps(chunk) {
+			continue
+		}
+		opts.GeoM.Reset()
+		opts.GeoM.Translate(geom.CFloat(r.Min.Sub(origin)))
+		dst.DrawImage(w.Sheet.SubImage(u.Tile.Cell()), &opts)
+	}
+}
+
+// Invalidate discards the cache. Call this after changing Units, or hiding or
+// showing units.
+func (w *Wall) Invalidate() {
+	w.cache.invalidate()
+	w.cacheValid = false
+}
+
+// Unload disposes of the cached images.
+func (w *Wall) Unload() { w.Invalidate() }
+
+// Load instantiates animations for all AnimatedTiles.
+func (w *Wall) Load(fs.FS) error {
+	for _, u := range w.Units {
+		at, ok := u.Tile.(*AnimatedTile)
+		if !ok {
+			continue
+		}
+		at.anim = w.Sheet.NewAnim(at.AnimKey)
+		if at.anim == nil {
+			return fmt.Errorf("missing anim %q", at.AnimKey)
+		}
+	}
+	return nil
+}
+
 // Scan visits &w.Sheet and all WallUnits.
 func (w *Wall) Scan(visit VisitFunc) error {
 	if err := visit(&w.Sheet); err != nil {
@@ -116,11 +188,26 @@ type WallUnit struct {
 	wall *Wall
 }
 
-// Draw draws this wall unit.
+// Draw draws this wall unit, unless the wall draws it from the cache.
 func (u *WallUnit) Draw(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
+	if u.wall.Cached && u.static() {
+		return
+	}
 	screen.DrawImage(u.wall.Sheet.SubImage(u.Tile.Cell()), opts)
 }
 
+// rect returns the rectangle the unit draws to, in wall coordinates.
+func (u *WallUnit) rect() image.Rectangle {
+	min := geom.CMul(u.pos, u.wall.UnitSize).Add(u.wall.UnitOffset)
+	return image.Rectangle{min, min.Add(u.wall.Sheet.CellSize)}
+}
+
+// static reports if the unit's tile is not animated.
+func (u *WallUnit) static() bool {
+	_, animated := u.Tile.(*AnimatedTile)
+	return !animated
+}
+
 // Scan visits u.Tile.
 func (u *WallUnit) Scan(visit VisitFunc) error {
 	return visit(u.Tile)
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// XRayMode values choose how DrawDAG shows an XRayTarget that is covered by
+// other drawers.
+type XRayMode int
+
+const (
+	XRayOff        XRayMode = iota // no special treatment
+	XRayFade                       // draw the occluders semi-transparent
+	XRaySilhouette                 // draw a silhouette of the target over the occluders
+)
+
+// XRay configures an XRayTarget.
+type XRay struct {
+	Mode XRayMode
+
+	// Alpha is the alpha of occluders (XRayFade) or of the silhouette
+	// (XRaySilhouette). Zero means 0.5.
+	Alpha float64
+
+	// Colour is the colour of the silhouette. nil means white.
+	Colour color.Color
+}
+
+// alpha returns Alpha or the default.
+func (x *XRay) alpha() float64 {
+	if x.Alpha == 0 {
+		return 0.5
+	}
+	return x.Alpha
+}
+
+// silhouette returns a colour matrix that turns everything into the
+// silhouette colour, with the silhouette alpha.
+func (x *XRay) silhouette() (cm ebiten.ColorM) {
+	r, g, b := 1.0, 1.0, 1.0
+	if x.Colour != nil {
+		c := color.NRGBAModel.Convert(x.Colour).(color.NRGBA)
+		r, g, b = float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	}
+	cm.Scale(0, 0, 0, x.alpha())
+	cm.Translate(r, g, b, 0)
+	return cm
+}
+
+// occluderAlpha returns the alpha to draw occluder o with, when it covers a
+// target using settings x.
+func occluderAlpha(o Drawer, x *XRay) float64 {
+	if xo, ok := o.(XRayOccluder); ok {
+		if a := xo.XRayAlpha(); a != 0 {
+			return a
+		}
+	}
+	return x.alpha()
+}
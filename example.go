@@ -25,6 +25,7 @@ import (
 	"image"
 	"image/color"
 	_ "image/png"
+	"io/fs"
 	"log"
 	"math"
 	"os"
@@ -63,12 +64,19 @@ func main() {
 	ebiten.SetWindowSize(640, 480)
 	ebiten.SetWindowTitle("TODO")
 
+	// Outside the browser, read assets from (and save them to) the source
+	// tree, falling back to the embedded copies.
+	assets := fs.FS(example.Assets)
+	if runtime.GOOS != "js" {
+		assets = &engine.OverlayFS{Base: example.Assets, Dir: "example"}
+	}
+
 	// Change to true to rewrite level1.gobz
 	lev1 := any(&engine.SceneRef{Path: "assets/level1.gobz"})
 	if hardcodedLevel1 {
 		lev1 = example.Level1()
 		if rewriteLevel1 && runtime.GOOS != "js" {
-			if err := engine.SaveGobz(lev1, "example/assets/level1.gobz"); err != nil {
+			if err := engine.SaveGobzFS(lev1, assets, "assets/level1.gobz"); err != nil {
 				log.Fatalf("Couldn't save level1.gobz: %v", err)
 			}
 		}
@@ -76,6 +84,7 @@ func main() {
 
 	g := &engine.Game{
 		ScreenSize: image.Pt(320, 240), // Window interior is this many pixels.
+		Scaling:    engine.ScaleInteger,
 		// TODO: refactor Projection and VoxelScale into... Scene? Camera?
 		// We might want different projections and scales in different levels.
 		Projection: geom.SimpleProjection{},
@@ -106,12 +115,12 @@ func main() {
 			),
 		},
 	}
-	if err := g.LoadAndPrepare(example.Assets); err != nil {
+	if err := g.LoadAndPrepare(assets); err != nil {
 		log.Fatalf("Loading/preparing error: %v", err)
 	}
 
 	if enableREPL && runtime.GOOS != "js" {
-		go g.REPL(os.Stdin, os.Stdout, example.Assets)
+		go g.REPL(os.Stdin, os.Stdout, assets)
 	}
 
 	if err := ebiten.RunGame(g); err != nil {
@@ -27,46 +27,51 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
-const awakemanProducesBubbles = true
-
 var _ interface {
+	engine.BoundingBoxer
 	engine.Identifier
 	engine.Disabler
 	engine.Prepper
 	engine.Scanner
+	engine.Snapshotter
 	engine.Updater
 } = &Awakeman{}
 
 func init() {
 	gob.Register(&Awakeman{})
+	gob.Register(awakemanState{})
 }
 
 // Awakeman is a bit of a god object for now...
 type Awakeman struct {
 	engine.Disables
-	Sprite   engine.Sprite
-	CameraID string
-	ToastID  string
+	Sprite             engine.Sprite
+	CameraControllerID string
+	ToastID            string
+	BubblesID          string
 
 	game        *engine.Game
-	camera      *engine.Camera
+	camera      *engine.CameraController
 	toast       *engine.DebugToast
+	bubbles     *engine.Emitter
 	vel         geom.Float3
 	facingLeft  bool
 	coyoteTimer int
 	jumpBuffer  int
 	noclip      bool
 	spawnPoint  geom.Int3
-	bubbleTimer int
 
 	anims map[string]*engine.Anim
 }
 
+// BoundingBox returns the sprite's bounding box (so the camera can follow).
+func (aw *Awakeman) BoundingBox() geom.Box { return aw.Sprite.BoundingBox() }
+
 // Ident returns "awakeman". There should be only one!
 func (aw *Awakeman) Ident() string { return "awakeman" }
 
 // Update updates Awakeman, including capturing input, applying gravity and
-// movement, and repositioning the camera.
+// movement, and choosing the camera zoom.
 func (aw *Awakeman) Update() error {
 	// TODO: better cheat for noclip
 	if inpututil.IsKeyJustPressed(ebiten.KeyN) {
@@ -88,29 +93,29 @@ func (aw *Awakeman) Update() error {
 		return err
 	}
 
-	// Update the camera
-	// aw.Pos is top-left corner, so add half size to get centre
-	z := 1.0
+	// Update the camera zoom (the controller follows aw)
+	aw.camera.Zoom = 1.0
 	if ebiten.IsKeyPressed(ebiten.KeyShift) {
-		z = 2.0
+		aw.camera.Zoom = 2.0
 	}
-	aw.camera.PointAt(aw.Sprite.Actor.BoundingBox().Centre(), z)
 	return nil
 }
 
 func (aw *Awakeman) noclipUpdate() error {
+	pos := aw.Sprite.Actor.Pos
 	if ebiten.IsKeyPressed(ebiten.KeyUp) {
-		aw.Sprite.Actor.Pos.Y--
+		pos.Y--
 	}
 	if ebiten.IsKeyPressed(ebiten.KeyDown) {
-		aw.Sprite.Actor.Pos.Y++
+		pos.Y++
 	}
 	if ebiten.IsKeyPressed(ebiten.KeyLeft) {
-		aw.Sprite.Actor.Pos.X--
+		pos.X--
 	}
 	if ebiten.IsKeyPressed(ebiten.KeyRight) {
-		aw.Sprite.Actor.Pos.X++
+		pos.X++
 	}
+	aw.Sprite.Actor.SetPos(pos)
 	return nil
 }
 
@@ -126,30 +131,18 @@ func (aw *Awakeman) realUpdate() error {
 		coyoteTime     = 5
 		jumpBufferTime = 5
 		respawnY       = 1000
-		bubblePeriod   = 6
+		hardLanding    = 4.5
+		landingTrauma  = 0.5
 	)
 
-	if awakemanProducesBubbles {
-		// Add a bubble?
-		aw.bubbleTimer--
-		if aw.bubbleTimer <= 0 {
-			aw.bubbleTimer = bubblePeriod
-			bubble := NewBubble(aw.Sprite.Actor.Pos.Add(geom.Pt3(-3, -20, -1)))
-			if err := aw.game.Load(bubble, Assets); err != nil {
-				return err
-			}
-			// Add bubble to same parent as aw
-			aw.game.PathRegister(bubble, aw.game.Parent(aw))
-			if err := aw.game.Prepare(bubble); err != nil {
-				return err
-			}
-			bubble.Sprite.SetAnim(bubble.Sprite.Sheet.NewAnim("bubble"))
-		}
+	if aw.bubbles != nil {
+		// Bubbles come out of the top of Awakeman's head.
+		aw.bubbles.Pos = aw.Sprite.Actor.Pos.Add(geom.Pt3(-3, -20, -1))
 	}
 
 	// Fell below some threshold?
 	if aw.Sprite.Actor.Pos.Y > respawnY {
-		aw.Sprite.Actor.Pos = aw.spawnPoint
+		aw.Sprite.Actor.SetPos(aw.spawnPoint)
 		aw.vel = geom.Float3{}
 	}
 
@@ -230,17 +223,16 @@ func (aw *Awakeman) realUpdate() error {
 
 	// vz == 0 for all remaining cases
 	case aw.vel.X < 0: // Left
-		aw.Sprite.SetAnim(aw.anims["run_left"])
+		aw.Sprite.SetAnim(aw.anims["run"])
 		aw.facingLeft = true
 	case aw.vel.X > 0: // Right
-		aw.Sprite.SetAnim(aw.anims["run_right"])
+		aw.Sprite.SetAnim(aw.anims["run"])
 		aw.facingLeft = false
 	default: // aw.velocity.X == 0; Idle
-		aw.Sprite.SetAnim(aw.anims["idle_right"])
-		if aw.facingLeft {
-			aw.Sprite.SetAnim(aw.anims["idle_left"])
-		}
+		aw.Sprite.SetAnim(aw.anims["idle"])
 	}
+	// The sheet only has right-facing frames, and run_vert is never mirrored.
+	aw.Sprite.FlipX = aw.facingLeft && aw.vel.Z == 0
 
 	// s = (v_0 + v) / 2.
 	aw.Sprite.Actor.MoveX((v0.X+aw.vel.X)/2, nil)
@@ -248,6 +240,10 @@ func (aw *Awakeman) realUpdate() error {
 	// Does not apply to X because controls override it anyway.
 	aw.Sprite.Actor.MoveY((v0.Y+aw.vel.Y)/2, func() {
 		if aw.vel.Y > 0 {
+			// Landed. Shake the camera if it was a hard landing.
+			if aw.vel.Y > hardLanding {
+				aw.camera.AddTrauma(landingTrauma)
+			}
 			return
 		}
 		aw.vel.Y *= restitution
@@ -262,9 +258,9 @@ func (aw *Awakeman) realUpdate() error {
 // Prepare captures necessary references to other game components.
 func (aw *Awakeman) Prepare(game *engine.Game) error {
 	aw.game = game
-	cam, ok := game.Component(aw.CameraID).(*engine.Camera)
+	cam, ok := game.Component(aw.CameraControllerID).(*engine.CameraController)
 	if !ok {
-		return fmt.Errorf("component %q not *engine.Camera", aw.CameraID)
+		return fmt.Errorf("component %q not *engine.CameraController", aw.CameraControllerID)
 	}
 	aw.camera = cam
 	tst, ok := game.Component(aw.ToastID).(*engine.DebugToast)
@@ -272,17 +268,82 @@ func (aw *Awakeman) Prepare(game *engine.Game) error {
 		return fmt.Errorf("component %q not *engine.DebugToast", aw.ToastID)
 	}
 	aw.toast = tst
+	if aw.BubblesID != "" {
+		bs, ok := game.Component(aw.BubblesID).(*engine.Emitter)
+		if !ok {
+			return fmt.Errorf("component %q not *engine.Emitter", aw.BubblesID)
+		}
+		aw.bubbles = bs
+	}
 	aw.anims = aw.Sprite.Sheet.NewAnims()
 	aw.spawnPoint = aw.Sprite.Actor.Pos
 
 	return nil
 }
 
+// Restore restores Awakeman from a snapshot.
+func (aw *Awakeman) Restore(s any) error {
+	st, ok := s.(awakemanState)
+	if !ok {
+		return fmt.Errorf("snapshot type %T != awakemanState", s)
+	}
+	aw.Sprite.Actor.SetState(st.Actor)
+	if a := aw.anims[st.AnimKey]; a != nil {
+		aw.Sprite.SetAnim(a)
+		a.Index, a.Ticks = st.AnimIndex, st.AnimTicks
+	}
+	aw.vel = st.Vel
+	aw.facingLeft = st.FacingLeft
+	aw.Sprite.FlipX = aw.facingLeft && aw.vel.Z == 0
+	aw.coyoteTimer = st.CoyoteTimer
+	aw.jumpBuffer = st.JumpBuffer
+	aw.noclip = st.Noclip
+	aw.spawnPoint = st.SpawnPoint
+	return nil
+}
+
 // Scan visits &aw.Sprite.
 func (aw *Awakeman) Scan(visit engine.VisitFunc) error {
 	return visit(&aw.Sprite)
 }
 
+// Snapshot returns the dynamic state of Awakeman.
+func (aw *Awakeman) Snapshot() (any, error) {
+	st := awakemanState{
+		Actor:       aw.Sprite.Actor.State(),
+		Vel:         aw.vel,
+		FacingLeft:  aw.facingLeft,
+		CoyoteTimer: aw.coyoteTimer,
+		JumpBuffer:  aw.jumpBuffer,
+		Noclip:      aw.noclip,
+		SpawnPoint:  aw.spawnPoint,
+	}
+	if a := aw.Sprite.Anim(); a != nil {
+		for k, v := range aw.anims {
+			if v == a {
+				st.AnimKey = k
+				break
+			}
+		}
+		st.AnimIndex, st.AnimTicks = a.Index, a.Ticks
+	}
+	return st, nil
+}
+
 func (aw *Awakeman) String() string {
 	return fmt.Sprintf("Awakeman@%v", aw.Sprite.Actor.Pos)
 }
+
+// awakemanState is the snapshot of Awakeman.
+type awakemanState struct {
+	Actor       engine.ActorState
+	AnimKey     string
+	AnimIndex   int
+	AnimTicks   int
+	Vel         geom.Float3
+	FacingLeft  bool
+	CoyoteTimer int
+	JumpBuffer  int
+	Noclip      bool
+	SpawnPoint  geom.Int3
+}
@@ -40,15 +40,34 @@ func Level1() *engine.Scene {
 					Pos: geom.Pt3(-160, -20, -100),
 					Src: engine.ImageRef{Path: "assets/space.png"},
 				},
-				Factor: 0.5,
+				FocalLength: 100, // bg_image is 100 deep, so factor 0.5
 			}, // Parallax
 			&engine.DrawDAG{
 				ChunkSize: 16,
 				Child: engine.MakeContainer(
 					level1PrismMap(),
 					level1Awakeman(),
+					&engine.Shadow{
+						TargetID:        "awakeman",
+						CollisionDomain: "level_1",
+						Src:             engine.ImageRef{Path: "assets/shadow.png"},
+						MaxDistance:     160,
+						FarScale:        0.25,
+					},
+					level1Bubbles(),
 				), // Container
 			}, // DrawDAG
+			&engine.CameraController{
+				ID:          "camera_controller",
+				CameraID:    "game_camera",
+				TargetID:    "awakeman",
+				Smoothing:   8,
+				Deadzone:    image.Rect(-16, -24, 16, 24),
+				Lookahead:   12,
+				ShakeOffset: 6,
+				ShakeAngle:  0.03,
+				TraumaDecay: 0.03,
+			},
 		), // Container
 	} // Scene
 }
@@ -283,10 +302,48 @@ func level1PrismMap() *engine.PrismMap {
 	} // PrismMap
 }
 
+func level1Bubbles() *engine.Emitter {
+	return &engine.Emitter{
+		ID:     "bubbles",
+		Spread: geom.Box{Max: geom.Pt3(1, 1, 1)},
+		Sheet: engine.Sheet{
+			AnimDefs: map[string]*engine.AnimDef{
+				"bubble": {
+					Steps: []engine.AnimStep{
+						{Cell: 0, Duration: 5},
+						{Cell: 1, Duration: 15},
+						{Cell: 2, Duration: 20},
+						{Cell: 3, Duration: 15},
+						{Cell: 4, Duration: 3},
+						{Cell: 5, Duration: 2},
+					},
+					OneShot: true,
+				},
+			},
+			CellSize: image.Pt(8, 8),
+			Src:      engine.ImageRef{Path: "assets/bubble.png"},
+		},
+		Anim:            "bubble",
+		MaxParticles:    16,
+		Rate:            1.0 / 6,
+		Lifetime:        60,
+		Velocity:        geom.Float3{Y: -1},
+		VelocityJitter:  geom.Float3{X: 0.5, Z: 0.5},
+		Wander:          geom.Float3{X: 0.1, Z: 0.1},
+		CollisionDomain: "level_1",
+		Bounds: geom.Box{
+			Min: geom.Pt3(-4, -4, -4),
+			Max: geom.Pt3(4, 4, 4),
+		},
+		DieOnCollide: true,
+	}
+}
+
 func level1Awakeman() *Awakeman {
 	return &Awakeman{
-		CameraID: "game_camera",
-		ToastID:  "toast",
+		CameraControllerID: "camera_controller",
+		ToastID:            "toast",
+		BubblesID:          "bubbles",
 		Sprite: engine.Sprite{
 			Actor: engine.Actor{
 				CollisionDomain: "level_1",
@@ -296,22 +353,14 @@ func level1Awakeman() *Awakeman {
 					Max: geom.Pt3(4, 1, 1),
 				},
 			},
-			DrawOffset: image.Pt(-5, -15),
+			DrawOffset:  image.Pt(-5, -15),
+			NotifyMoves: true, // Awakeman only moves the Actor with its methods
 			Sheet: engine.Sheet{
 				AnimDefs: map[string]*engine.AnimDef{
-					"idle_left": {Steps: []engine.AnimStep{
-						{Cell: 1, Duration: 60},
-					}},
-					"idle_right": {Steps: []engine.AnimStep{
+					"idle": {Steps: []engine.AnimStep{
 						{Cell: 0, Duration: 60},
 					}},
-					"run_left": {Steps: []engine.AnimStep{
-						{Cell: 14, Duration: 3},
-						{Cell: 15, Duration: 5},
-						{Cell: 16, Duration: 3},
-						{Cell: 17, Duration: 3},
-					}},
-					"run_right": {Steps: []engine.AnimStep{
+					"run": {Steps: []engine.AnimStep{
 						{Cell: 10, Duration: 3},
 						{Cell: 11, Duration: 5},
 						{Cell: 12, Duration: 3},
@@ -327,13 +376,7 @@ func level1Awakeman() *Awakeman {
 						{Cell: 24, Duration: 3},
 						{Cell: 25, Duration: 3},
 					}},
-					"walk_left": {Steps: []engine.AnimStep{
-						{Cell: 2, Duration: 6},
-						{Cell: 3, Duration: 6},
-						{Cell: 4, Duration: 6},
-						{Cell: 5, Duration: 6},
-					}},
-					"walk_right": {Steps: []engine.AnimStep{
+					"walk": {Steps: []engine.AnimStep{
 						{Cell: 6, Duration: 6},
 						{Cell: 7, Duration: 6},
 						{Cell: 8, Duration: 6},
@@ -343,6 +386,10 @@ func level1Awakeman() *Awakeman {
 				CellSize: image.Pt(10, 16),
 				Src:      engine.ImageRef{Path: "assets/aw.png"},
 			}, // Sheet
+			XRay: &engine.XRay{
+				Mode:  engine.XRayFade,
+				Alpha: 0.4,
+			},
 		}, // Sprite
 	} // Awakeman
 }
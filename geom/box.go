@@ -73,6 +73,36 @@ func (b Box) Sub(p Int3) Box {
 	}
 }
 
+// Union returns the smallest box containing both b and c. If either box is
+// empty, the other is returned.
+func (b Box) Union(c Box) Box {
+	if b.Empty() {
+		return c
+	}
+	if c.Empty() {
+		return b
+	}
+	if b.Min.X > c.Min.X {
+		b.Min.X = c.Min.X
+	}
+	if b.Min.Y > c.Min.Y {
+		b.Min.Y = c.Min.Y
+	}
+	if b.Min.Z > c.Min.Z {
+		b.Min.Z = c.Min.Z
+	}
+	if b.Max.X < c.Max.X {
+		b.Max.X = c.Max.X
+	}
+	if b.Max.Y < c.Max.Y {
+		b.Max.Y = c.Max.Y
+	}
+	if b.Max.Z < c.Max.Z {
+		b.Max.Z = c.Max.Z
+	}
+	return b
+}
+
 // Canon returns a copy of b that is well-formed.
 func (b Box) Canon() Box {
 	if b.Max.X < b.Min.X {
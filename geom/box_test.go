@@ -0,0 +1,43 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import "testing"
+
+func TestBoxUnion(t *testing.T) {
+	a := Box{Min: Pt3(0, 0, 0), Max: Pt3(2, 2, 2)}
+	b := Box{Min: Pt3(-1, 1, 1), Max: Pt3(1, 3, 5)}
+	empty := Box{Min: Pt3(5, 5, 5), Max: Pt3(5, 6, 7)}
+
+	tests := []struct {
+		b, c, want Box
+	}{
+		{a, b, Box{Min: Pt3(-1, 0, 0), Max: Pt3(2, 3, 5)}},
+		{b, a, Box{Min: Pt3(-1, 0, 0), Max: Pt3(2, 3, 5)}},
+		{a, a, a},
+		{a, empty, a},
+		{empty, a, a},
+		{Box{}, b, b},
+		{b, Box{}, b},
+		{Box{}, Box{}, Box{}},
+	}
+	for _, test := range tests {
+		if got := test.b.Union(test.c); got != test.want {
+			t.Errorf("%v.Union(%v) = %v, want %v", test.b, test.c, got, test.want)
+		}
+	}
+}
@@ -30,6 +30,12 @@ func CDiv(p, q image.Point) image.Point {
 	return image.Point{p.X / q.X, p.Y / q.Y}
 }
 
+// CFloorDiv performs componentwise floored division of two image.Points (see
+// FloorDiv).
+func CFloorDiv(p, q image.Point) image.Point {
+	return image.Point{FloorDiv(p.X, q.X), FloorDiv(p.Y, q.Y)}
+}
+
 // CFloat returns the components of an image.Point as two floats.
 func CFloat(p image.Point) (x, y float64) {
 	return float64(p.X), float64(p.Y)
@@ -47,6 +53,16 @@ func CSign(p image.Point) image.Point {
 
 // ---------- Some other helpers ----------
 
+// FloorDiv returns a/b rounded towards negative infinity (unlike a/b, which
+// rounds towards zero).
+func FloorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
 // FSign returns the sign of the float64 (-1, 0, or 1).
 func FSign(m float64) float64 {
 	if m == 0 {
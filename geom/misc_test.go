@@ -0,0 +1,63 @@
+/*
+Copyright 2021 Josh Deprez
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"image"
+	"testing"
+)
+
+func TestFloorDiv(t *testing.T) {
+	tests := []struct {
+		a, b, want int
+	}{
+		{7, 2, 3},
+		{8, 2, 4},
+		{0, 3, 0},
+		{-1, 16, -1},
+		{-5, 16, -1},
+		{-16, 16, -1},
+		{-17, 16, -2},
+		{-32, 16, -2},
+		{7, -2, -4},
+		{8, -2, -4},
+		{-7, -2, 3},
+		{-8, -2, 4},
+	}
+	for _, test := range tests {
+		if got := FloorDiv(test.a, test.b); got != test.want {
+			t.Errorf("FloorDiv(%d, %d) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestCFloorDiv(t *testing.T) {
+	tests := []struct {
+		p, q, want image.Point
+	}{
+		{image.Pt(33, 17), image.Pt(16, 16), image.Pt(2, 1)},
+		{image.Pt(32, 16), image.Pt(16, 16), image.Pt(2, 1)},
+		{image.Pt(-5, -5), image.Pt(16, 16), image.Pt(-1, -1)},
+		{image.Pt(-16, -32), image.Pt(16, 16), image.Pt(-1, -2)},
+		{image.Pt(-17, 5), image.Pt(16, 8), image.Pt(-2, 0)},
+	}
+	for _, test := range tests {
+		if got := CFloorDiv(test.p, test.q); got != test.want {
+			t.Errorf("CFloorDiv(%v, %v) = %v, want %v", test.p, test.q, got, test.want)
+		}
+	}
+}